@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Constants from <linux/capability.h>.
+const (
+	vfsCapRevisionMask = 0xff000000
+	vfsCapRevision1    = 0x01000000
+	vfsCapEffective    = 0x000001
+)
+
+// capNames maps Linux capability numbers to their names, as used by getcap(8).
+var capNames = [...]string{
+	"cap_chown", "cap_dac_override", "cap_dac_read_search", "cap_fowner",
+	"cap_fsetid", "cap_kill", "cap_setgid", "cap_setuid", "cap_setpcap",
+	"cap_linux_immutable", "cap_net_bind_service", "cap_net_broadcast",
+	"cap_net_admin", "cap_net_raw", "cap_ipc_lock", "cap_ipc_owner",
+	"cap_sys_module", "cap_sys_rawio", "cap_sys_chroot", "cap_sys_ptrace",
+	"cap_sys_pacct", "cap_sys_admin", "cap_sys_boot", "cap_sys_nice",
+	"cap_sys_resource", "cap_sys_time", "cap_sys_tty_config", "cap_mknod",
+	"cap_lease", "cap_audit_write", "cap_audit_control", "cap_setfcap",
+	"cap_mac_override", "cap_mac_admin", "cap_syslog", "cap_wake_alarm",
+	"cap_block_suspend", "cap_audit_read", "cap_perfmon", "cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// fileCapabilities returns the file capabilities of path in the textual
+// form used by getcap(8) (e.g. "cap_net_raw=ep"), or "" if it has none.
+func fileCapabilities(path string) string {
+	buf := make([]byte, 24) // large enough for struct vfs_ns_cap_data
+	n, err := unix.Lgetxattr(path, "security.capability", buf)
+	if err != nil || n < 4 {
+		return ""
+	}
+	buf = buf[:n]
+
+	// The data starts with a version and flags word followed by one
+	// (version 1) or two (versions 2 and 3) pairs of permitted and
+	// inheritable capability masks.
+	magic := binary.LittleEndian.Uint32(buf)
+	effective := magic&vfsCapEffective != 0
+	words := 2
+	if magic&vfsCapRevisionMask == vfsCapRevision1 {
+		words = 1
+	}
+	if len(buf) < 4+8*words {
+		return ""
+	}
+	var permitted, inheritable uint64
+	for i := range words {
+		permitted |= uint64(binary.LittleEndian.Uint32(buf[4+8*i:])) << (32 * i)
+		inheritable |= uint64(binary.LittleEndian.Uint32(buf[8+8*i:])) << (32 * i)
+	}
+
+	// Group capabilities with the same flags, like getcap does.
+	var groups []string
+	caps := map[string][]string{}
+	for i := range 64 {
+		p := permitted&(1<<i) != 0
+		in := inheritable&(1<<i) != 0
+		if !p && !in {
+			continue
+		}
+		var flags string
+		if effective {
+			flags += "e"
+		}
+		if in {
+			flags += "i"
+		}
+		if p {
+			flags += "p"
+		}
+		if _, ok := caps[flags]; !ok {
+			groups = append(groups, flags)
+		}
+		name := "cap_" + strconv.Itoa(i)
+		if i < len(capNames) {
+			name = capNames[i]
+		}
+		caps[flags] = append(caps[flags], name)
+	}
+
+	parts := make([]string, len(groups))
+	for i, flags := range groups {
+		parts[i] = strings.Join(caps[flags], ",") + "=" + flags
+	}
+	return strings.Join(parts, " ")
+}
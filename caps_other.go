@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// fileCapabilities returns the file capabilities of path, which are only
+// supported on Linux.
+func fileCapabilities(path string) string {
+	return ""
+}
@@ -9,72 +9,185 @@ import (
 	"runtime"
 	"runtime/debug"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
 
 // usageLine is the synopsis printed on flag parse errors.
-const usageLine = `usage: %s [-h] [-V] [-a] [-d] [-l] [-r] [-1] [-dirsfirst] [-git]
-            [-sort WORD] [file ...]
+const usageLine = `usage: %s [-h] [-V] [-a] [-d] [-l] [-r] [-R] [-1] [-n] [-Z] [--dirsfirst]
+            [--git] [--sort KEYS] [--tree] [--depth N] [--json] [--ndjson]
+            [--time-field WORD] [--header] [--columns LIST] [--xattr]
+            [--flags] [--size-style WORD] [--thousands] [--du]
+            [--cross-mounts] [--summary] [--reltime]
+            [--time-style STYLE] [--tz ZONE] [--recent DURATION]
+            [file ...]
 `
 
-// helpMessage is the full help text printed for -h/-help.
+// helpMessage is the full help text printed for -h/--help.
 const helpMessage = `
 myls - My interpretation of the ls(1) command
 
 positional arguments:
-  file          files or directories to display
+  file                files or directories to display
 
 options:
-  -h, -help     show this help message and exit
-  -V, -version  show program's version number and exit
-  -a            do not ignore entries starting with .
-  -d            list directories themselves, not their contents
-  -l            use a long listing format
-  -r            reverse order while sorting
-  -1            display one entry per line
-  -dirsfirst    show directories above regular files
-  -git          display git status
-  -sort WORD    one of: name, extension, size, time, git (default: name)
+  -h, --help          show this help message and exit
+  -V, --version       show program's version number and exit
+  -a, --all           do not ignore entries starting with .
+  -d, --directory     list directories themselves, not their contents
+  -l, --long          use a long listing format
+  -r, --reverse       reverse order while sorting
+  -R, --recursive     list subdirectories recursively
+  -1                  display one entry per line
+  -n, --numeric-uid-gid
+                      list numeric user and group IDs
+  -Z, --context       print the SELinux security context of each entry
+  --dirsfirst         show directories above regular files
+  --git               display git status
+  --sort KEYS         comma-separated list of: name, extension, size, time,
+                      git, natural, none; prefix a key with - to reverse it
+                      (default: name)
+  --tree              display directories as a tree
+  --depth N           limit -R and --tree to N levels of depth
+                      (default: unlimited)
+  --json              print entries and errors as JSON, grouped by directory
+  --ndjson            print one JSON object per entry or error; with
+                      --sort none, entries are printed as soon as they are
+                      read, without dir_count
+  --time-field WORD   timestamp to show and sort by: mtime, atime, ctime,
+                      btime (default: mtime)
+  --header            print a header row above long listings
+  --columns LIST      comma-separated list of long listing columns: inode,
+                      mode, flags, links, owner, group, context, size, time,
+                      git, name (added last if left out)
+                      (default: mode,owner,group,size,time,git,name)
+  --xattr             list extended attribute names and sizes below each
+                      entry in long listings
+  --flags             show inode flags like lsattr(1) in long listings
+                      (Linux only)
+  --size-style WORD   how to show and sort file sizes: binary (1024-based),
+                      si (1000-based), bytes (exact), blocks (allocated disk
+                      space) (default: binary)
+  --thousands         group the digits of exact sizes with commas
+  --du                show and sort directories by the total size of their
+                      contents instead of the number of items inside; unlike
+                      du, a file hard-linked into several directories counts
+                      toward each of them
+  --cross-mounts      with --du, include directories on other file systems
+  --summary           print the number of entries by type and their total
+                      size after each listing
+  --reltime           show times relative to now (e.g. 5m, 3h, 2d, 4mo)
+  --time-style STYLE  time format: full-iso, long-iso, iso, locale, relative,
+                      or +FORMAT, where FORMAT is a Go layout or strftime
+                      format, or two of them for non-recent and recent files
+                      separated by a newline
+  --tz ZONE           show times in ZONE (e.g. UTC, Europe/Berlin) instead
+                      of the local time zone
+  --recent DURATION   use the recent time format for files modified less
+                      than DURATION ago, e.g. 6mo, 30d, 1d12h (default: 6mo)
+
+  Short options can be combined (e.g. -la). Long options take values after
+  '=' or as the next argument (e.g. --sort=size, --sort size); --dirsfirst,
+  --git, --sort, --help and --version also work with a single dash. Use -- to
+  end the options.
 
 environment:
   MYLS_TIMEFMT_OLD, MYLS_TIMEFMT_NEW
-                used to specify the time format for non-recent and recent files
-  MYLS_DIRS_FIRST
-                if set to a true boolean value, enables -dirsfirst by default
-  MYLS_GIT      if set to a true boolean value, enables -git by default
-  LS_COLORS     used to specify the colours for file types and file names
-  NO_COLOR      if set to a non-empty value, disables coloured output
+                      used to specify the time format for non-recent and
+                      recent (see --recent) files as a Go layout, a strftime
+                      format (if it contains '%') or "relative"
+  MYLS_TIME_STYLE     used to specify the default for --time-style; overrides
+                      MYLS_TIMEFMT_OLD and MYLS_TIMEFMT_NEW
+  MYLS_RECENT         used to specify the default for --recent
+  MYLS_DIRS_FIRST     if set to a true boolean value, enables --dirsfirst by
+                      default
+  MYLS_GIT            if set to a true boolean value, enables --git by default
+  MYLS_HEADER         if set to a true boolean value, enables --header by
+                      default
+  MYLS_COLUMNS        used to specify the default for --columns
+  MYLS_NATURAL_SORT   if set to a true boolean value, compares digits in names
+                      by their numeric value for every sort key
+  LS_COLORS           used to specify the colours for file types and file names
+  MYLS_COLORS         used to specify the colours for permissions, sizes,
+                      times, Git status codes and more (see README)
+  NO_COLOR            if set to a non-empty value, disables coloured output
+  TZ                  used to specify the local time zone
 `
 
 // options represents the program's runtime configuration.
 type options struct {
-	help      bool     // -h, -help
-	version   bool     // -V, -version
-	all       bool     // -a
-	dir       bool     // -d
-	long      bool     // -l
-	reverse   bool     // -r
-	oneEntry  bool     // -1
-	dirsFirst bool     // -dirsfirst
-	git       bool     // -git
-	sort      sortBy   // -sort
-	args      []string // non-flag command-line arguments
-
-	timeFmtOld string
-	timeFmtNew string
-	termWidth  int
+	help      bool       // -h, --help
+	version   bool       // -V, --version
+	all       bool       // -a, --all
+	dir       bool       // -d, --directory
+	long      bool       // -l, --long
+	reverse   bool       // -r, --reverse
+	recursive bool       // -R, --recursive
+	oneEntry  bool       // -1
+	numeric   bool       // -n, --numeric-uid-gid
+	context   bool       // -Z, --context
+	dirsFirst bool       // --dirsfirst
+	git       bool       // --git
+	sort      sortBy     // --sort
+	tree      bool       // --tree
+	depth     int        // --depth
+	json      bool       // --json
+	ndjson    bool       // --ndjson
+	timeField timeField  // --time-field
+	header    bool       // --header
+	columns   columnList // --columns
+	xattr     bool       // --xattr
+	flags     bool       // --flags
+	sizeStyle sizeStyle  // --size-style
+	thousands bool       // --thousands
+	du        bool       // --du
+	crossFS   bool       // --cross-mounts
+	summary   bool       // --summary
+	relTime   bool       // --reltime
+	timeStyle timeStyle  // --time-style
+	args      []string   // non-flag command-line arguments
+
+	timeFmtOld  string
+	timeFmtNew  string
+	termWidth   int
+	naturalSort bool
+	location    *time.Location // --tz
+	recent      time.Duration  // --recent
 }
 
 var opt options
 
 // initOptions initializes opt from environment variables and command-line flags.
-// It also handles -h/-help and -V/-version by printing a message and exiting.
+// It also handles -h/--help and -V/--version by printing a message and exiting.
 func initOptions() {
-	opt.timeFmtOld = cmp.Or(os.Getenv("MYLS_TIMEFMT_OLD"), "Jan _2  2006")
-	opt.timeFmtNew = cmp.Or(os.Getenv("MYLS_TIMEFMT_NEW"), "Jan _2 15:04")
+	opt.timeFmtOld = cmp.Or(os.Getenv("MYLS_TIMEFMT_OLD"), defaultTimeFmtOld)
+	opt.timeFmtNew = cmp.Or(os.Getenv("MYLS_TIMEFMT_NEW"), defaultTimeFmtNew)
+	if v := os.Getenv("MYLS_TIME_STYLE"); v != "" {
+		if err := opt.timeStyle.Set(v); err != nil {
+			showError(fmt.Errorf("invalid value %q for MYLS_TIME_STYLE: %v", v, err))
+		}
+	}
+	opt.location = time.Local
+	opt.recent = 6 * month
+	if v := os.Getenv("MYLS_RECENT"); v != "" {
+		if d, err := parseDuration(v); err != nil {
+			showError(fmt.Errorf("invalid value %q for MYLS_RECENT: %v", v, err))
+		} else {
+			opt.recent = d
+		}
+	}
 	opt.dirsFirst, _ = strconv.ParseBool(os.Getenv("MYLS_DIRS_FIRST"))
 	opt.git, _ = strconv.ParseBool(os.Getenv("MYLS_GIT"))
+	opt.header, _ = strconv.ParseBool(os.Getenv("MYLS_HEADER"))
+	if v := os.Getenv("MYLS_COLUMNS"); v != "" {
+		if err := opt.columns.Set(v); err != nil {
+			showError(fmt.Errorf("invalid value %q for MYLS_COLUMNS: %v", v, err))
+		}
+	}
+	opt.naturalSort, _ = strconv.ParseBool(os.Getenv("MYLS_NATURAL_SORT"))
 	width, _, _ := term.GetSize(int(os.Stdout.Fd()))
 	opt.termWidth = cmp.Or(width, 80) // Fallback for non-terminal output etc.
 
@@ -83,21 +196,63 @@ func initOptions() {
 	flag.BoolVar(&opt.version, "V", false, "")
 	flag.BoolVar(&opt.version, "version", false, "")
 	flag.BoolVar(&opt.all, "a", false, "")
+	flag.BoolVar(&opt.all, "all", false, "")
 	flag.BoolVar(&opt.dir, "d", false, "")
+	flag.BoolVar(&opt.dir, "directory", false, "")
 	flag.BoolVar(&opt.long, "l", false, "")
+	flag.BoolVar(&opt.long, "long", false, "")
 	flag.BoolVar(&opt.reverse, "r", false, "")
+	flag.BoolVar(&opt.reverse, "reverse", false, "")
+	flag.BoolVar(&opt.recursive, "R", false, "")
+	flag.BoolVar(&opt.recursive, "recursive", false, "")
 	flag.BoolVar(&opt.oneEntry, "1", false, "")
+	flag.BoolVar(&opt.numeric, "n", false, "")
+	flag.BoolVar(&opt.numeric, "numeric-uid-gid", false, "")
+	flag.BoolVar(&opt.context, "Z", false, "")
+	flag.BoolVar(&opt.context, "context", false, "")
 	flag.BoolVar(&opt.dirsFirst, "dirsfirst", opt.dirsFirst, "")
 	flag.BoolVar(&opt.git, "git", opt.git, "")
 	flag.Var(&opt.sort, "sort", "")
+	flag.BoolVar(&opt.tree, "tree", false, "")
+	flag.IntVar(&opt.depth, "depth", 0, "")
+	flag.BoolVar(&opt.json, "json", false, "")
+	flag.BoolVar(&opt.ndjson, "ndjson", false, "")
+	flag.Var(&opt.timeField, "time-field", "")
+	flag.BoolVar(&opt.header, "header", opt.header, "")
+	flag.Var(&opt.columns, "columns", "")
+	flag.BoolVar(&opt.xattr, "xattr", false, "")
+	flag.BoolVar(&opt.flags, "flags", false, "")
+	flag.Var(&opt.sizeStyle, "size-style", "")
+	flag.BoolVar(&opt.thousands, "thousands", false, "")
+	flag.BoolVar(&opt.du, "du", false, "")
+	flag.BoolVar(&opt.crossFS, "cross-mounts", false, "")
+	flag.BoolVar(&opt.summary, "summary", false, "")
+	flag.BoolVar(&opt.relTime, "reltime", false, "")
+	flag.Var(&opt.timeStyle, "time-style", "")
+	flag.Func("tz", "", func(s string) (err error) {
+		opt.location, err = time.LoadLocation(s)
+		return err
+	})
+	flag.Func("recent", "", func(s string) (err error) {
+		opt.recent, err = parseDuration(s)
+		return err
+	})
 
 	// If flag parsing fails, print the usage synopsis to stderr.
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), usageLine, progName)
 	}
-	flag.Parse()
+	args, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if err == nil && opt.depth < 0 {
+		err = fmt.Errorf("invalid value %d for flag --depth: must not be negative", opt.depth)
+	}
+	if err != nil {
+		fmt.Fprintln(flag.CommandLine.Output(), err)
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	// If -h or -help is set, print the full help text to stdout.
+	// If -h or --help is set, print the full help text to stdout.
 	if opt.help {
 		flag.CommandLine.SetOutput(os.Stdout)
 		flag.Usage()
@@ -110,7 +265,13 @@ func initOptions() {
 		os.Exit(0)
 	}
 
-	args := flag.Args()
+	if opt.timeStyle.name != "" {
+		opt.timeFmtOld, opt.timeFmtNew = opt.timeStyle.fmtOld, opt.timeStyle.fmtNew
+	}
+	if opt.relTime {
+		opt.timeFmtOld, opt.timeFmtNew = relativeFmt, relativeFmt
+	}
+
 	// Windows leaves glob expansion to the application.
 	// In this case, us.
 	if runtime.GOOS == "windows" {
@@ -122,6 +283,106 @@ func initOptions() {
 	opt.args = args
 }
 
+// singleDashFlags are the long flags that may also be given with a single
+// dash, as they could before myls followed GNU conventions.
+var singleDashFlags = map[string]bool{
+	"dirsfirst": true,
+	"git":       true,
+	"sort":      true,
+	"help":      true,
+	"version":   true,
+}
+
+// parseArgs parses args according to the flags defined in fs and returns
+// the remaining positional arguments.
+//
+// Unlike [flag.FlagSet.Parse], it follows POSIX and GNU conventions:
+// single-letter flags can be combined (-la), and a single-letter flag that
+// takes a value takes it from the rest of the argument or the next one
+// (-xVALUE, -x VALUE). Longer flags are written with two dashes, or with
+// one for those in [singleDashFlags], and take their value after '=' or
+// from the next argument (--sort=size, --sort size, -sort=size). Flags may
+// follow positional arguments, and "--" ends flag parsing.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+
+		if arg == "--" {
+			return append(rest, args...), nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			rest = append(rest, arg)
+			continue
+		}
+
+		dashes := "-"
+		if strings.HasPrefix(arg, "--") {
+			dashes = "--"
+		}
+		name, val, hasVal := strings.Cut(arg[len(dashes):], "=")
+
+		// Long flag, or a single dash followed by one of the few long
+		// flags that may be written that way. Anything else after a single
+		// dash is a group of single-letter flags, so -all means -a -l -l.
+		if dashes == "--" || singleDashFlags[name] {
+			f := fs.Lookup(name)
+			if f == nil || len(name) < 2 {
+				return nil, fmt.Errorf("flag provided but not defined: %s%s", dashes, name)
+			}
+			if !hasVal {
+				if isBoolFlag(f) {
+					val = "true"
+				} else if len(args) == 0 {
+					return nil, fmt.Errorf("flag needs an argument: %s%s", dashes, name)
+				} else {
+					val, args = args[0], args[1:]
+				}
+			}
+			if err := fs.Set(name, val); err != nil {
+				return nil, fmt.Errorf("invalid value %q for flag %s%s: %v", val, dashes, name, err)
+			}
+			continue
+		}
+
+		// Group of single-letter flags.
+		for i := 1; i < len(arg); {
+			_, size := utf8.DecodeRuneInString(arg[i:])
+			name := arg[i : i+size]
+			i += size
+
+			f := fs.Lookup(name)
+			if f == nil {
+				return nil, fmt.Errorf("flag provided but not defined: -%s", name)
+			}
+			if isBoolFlag(f) {
+				fs.Set(name, "true")
+				continue
+			}
+
+			val := arg[i:]
+			if val == "" {
+				if len(args) == 0 {
+					return nil, fmt.Errorf("flag needs an argument: -%s", name)
+				}
+				val, args = args[0], args[1:]
+			}
+			if err := fs.Set(name, val); err != nil {
+				return nil, fmt.Errorf("invalid value %q for flag -%s: %v", val, name, err)
+			}
+			break
+		}
+	}
+	return rest, nil
+}
+
+// isBoolFlag reports whether f is a boolean flag, which takes no argument.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
 // version returns the program name and version string.
 func version() string {
 	bi, ok := debug.ReadBuildInfo()
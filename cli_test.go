@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		flags string // set flags as name=value, sorted by name
+		rest  []string
+		err   string
+	}{
+		{nil, "", nil, ""},
+		{[]string{"-l"}, "l=true", nil, ""},
+		{[]string{"-la"}, "a=true l=true", nil, ""},
+		{[]string{"-1a"}, "1=true a=true", nil, ""},
+		{[]string{"-all"}, "a=true l=true", nil, ""},
+		{[]string{"--all"}, "all=true", nil, ""},
+		{[]string{"--all=false"}, "all=false", nil, ""},
+		{[]string{"-aD3"}, "D=3 a=true", nil, ""},
+		{[]string{"-aD", "3"}, "D=3 a=true", nil, ""},
+
+		// Long flags with values.
+		{[]string{"--sort=size"}, "sort=size", nil, ""},
+		{[]string{"--sort", "size"}, "sort=size", nil, ""},
+		{[]string{"--sort="}, "sort=", nil, ""},
+		{[]string{"--depth", "2", "dir"}, "depth=2", []string{"dir"}, ""},
+
+		// Single-dash long flags kept for compatibility.
+		{[]string{"-sort=size"}, "sort=size", nil, ""},
+		{[]string{"-sort", "size"}, "sort=size", nil, ""},
+		{[]string{"-git", "-dirsfirst"}, "dirsfirst=true git=true", nil, ""},
+
+		// Positional arguments.
+		{[]string{"a", "-l", "b"}, "l=true", []string{"a", "b"}, ""},
+		{[]string{"-"}, "", []string{"-"}, ""},
+		{[]string{"-l", "--", "-a", "--all"}, "l=true", []string{"-a", "--all"}, ""},
+		{[]string{"--", "--"}, "", []string{"--"}, ""},
+
+		// Errors.
+		{[]string{"--sort"}, "", nil, "flag needs an argument: --sort"},
+		{[]string{"-D"}, "", nil, "flag needs an argument: -D"},
+		{[]string{"-aD"}, "a=true", nil, "flag needs an argument: -D"},
+		{[]string{"--nope"}, "", nil, "flag provided but not defined: --nope"},
+		{[]string{"-q"}, "", nil, "flag provided but not defined: -q"},
+		{[]string{"-depth=2"}, "", nil, "flag provided but not defined: -d"},
+		{[]string{"--a"}, "", nil, "flag provided but not defined: --a"},
+		{[]string{"--depth=x"}, "", nil, `invalid value "x" for flag --depth: parse error`},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		for _, name := range []string{"a", "l", "1", "all", "dirsfirst", "git"} {
+			fs.Bool(name, false, "")
+		}
+		fs.String("sort", "name", "")
+		fs.Int("D", 0, "")
+		fs.Int("depth", 0, "")
+
+		rest, err := parseArgs(fs, tt.args)
+		var gotErr string
+		if err != nil {
+			gotErr = err.Error()
+		}
+		var set []string
+		fs.Visit(func(f *flag.Flag) {
+			set = append(set, fmt.Sprintf("%s=%s", f.Name, f.Value))
+		})
+
+		if !strings.HasPrefix(gotErr, tt.err) || (tt.err == "") != (gotErr == "") {
+			t.Errorf("parseArgs(%q): error %q, want %q", tt.args, gotErr, tt.err)
+		}
+		if got := strings.Join(set, " "); got != tt.flags {
+			t.Errorf("parseArgs(%q): set %q, want %q", tt.args, got, tt.flags)
+		}
+		if err == nil && !slices.Equal(rest, tt.rest) {
+			t.Errorf("parseArgs(%q) = %q, want %q", tt.args, rest, tt.rest)
+		}
+	}
+}
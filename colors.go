@@ -1,9 +1,12 @@
 package main
 
 import (
+	"cmp"
 	"os"
+	"runtime"
 	"slices"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 )
@@ -16,8 +19,10 @@ const (
 // colorConfig represents the programs's colour configuration.
 type colorConfig struct {
 	enabled  bool              // whether coloured output should be used
+	stderr   bool              // whether error messages should be coloured
 	types    map[string]string // $LS_COLORS type to colour sequence (e.g. "di", "ln")
 	suffixes []suffixRule      // filename suffix to colour sequence, kept in a slice for sorting
+	meta     map[string]string // $MYLS_COLORS key to colour sequence (e.g. "ur", "hd")
 }
 
 type suffixRule struct {
@@ -39,16 +44,62 @@ var colors = colorConfig{
 		"bd": "", // BLK
 		"su": "", // SETUID
 		"sg": "", // SETGID
+		"ca": "", // CAPABILITY
 		"ex": "", // EXEC
+		"mh": "", // MULTIHARDLINK
 		"fi": "", // FILE
 
 		/* not implemented */
 		"no": "", // NORMAL
 		"rs": "", // RESET
 		"do": "", // DOOR
-		"mh": "", // MULTIHARDLINK
 		"mi": "", // MISSING
-		"ca": "", // CAPABILITY
+	},
+	meta: map[string]string{
+		// permissions
+		"ur": "", // user read
+		"uw": "", // user write
+		"ux": "", // user execute
+		"gr": "", // group read
+		"gw": "", // group write
+		"gx": "", // group execute
+		"wr": "", // others (world) read
+		"ww": "", // others (world) write
+		"wx": "", // others (world) execute
+		"sp": "", // setuid, setgid and sticky bits
+		"xa": "", // ACL and extended attribute indicator
+
+		// sizes
+		"sn": "", // sizes and item counts without a more specific style
+		"nb": "", // sizes below 1 KiB
+		"nk": "", // sizes below 1 MiB
+		"nm": "", // sizes below 1 GiB
+		"ng": "", // sizes below 1 TiB
+		"nt": "", // larger sizes
+
+		// timestamps
+		"da": "", // timestamps without a more specific style
+		"dh": "", // timestamps less than an hour old
+		"dd": "", // timestamps less than a day old
+		"dw": "", // timestamps less than a week old
+
+		// git status codes
+		"ga": "32",   // added (A) or copied (C)
+		"gm": "33",   // modified (M)
+		"gd": "31",   // deleted (D)
+		"gv": "36",   // renamed (R)
+		"gt": "35",   // type changed (T)
+		"gc": "1;31", // conflicted (U, or AA/DD)
+		"gu": "90",   // untracked (?)
+		"gi": "2",    // ignored (!)
+		"sc": "1",    // added to the styles above for staged changes
+		"wc": "",     // added to the styles above for worktree changes
+
+		// other
+		"hd": "4", // header row of long listings
+		"dp": "",  // directory headers
+		"ar": "",  // arrow between symlinks and their targets
+		"er": "",  // error messages
 	},
 }
 
@@ -79,15 +130,34 @@ func (c *colorConfig) applyLSCOLORS(s string) {
 	})
 }
 
+// applyMYLSCOLORS parses a $MYLS_COLORS value, which has the same format
+// as $LS_COLORS, and updates c with its rules. Keys that are not specific
+// to $MYLS_COLORS are treated as in $LS_COLORS.
+func (c *colorConfig) applyMYLSCOLORS(s string) {
+	var rest []string
+	for ent := range strings.SplitSeq(s, ":") {
+		k, v, found := strings.Cut(ent, "=")
+		if _, ok := c.meta[k]; !ok || !found {
+			rest = append(rest, ent)
+			continue
+		}
+		if v == "0" || v == "00" {
+			v = ""
+		}
+		c.meta[k] = v
+	}
+	c.applyLSCOLORS(strings.Join(rest, ":"))
+}
+
 // initColors initialises the colour configuration from environment variables.
 func initColors() {
 	if os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
 		return
 	}
-	if v := os.Getenv("LS_COLORS"); v != "" {
-		colors.enabled = true
-		colors.applyLSCOLORS(v)
-	}
+	colors.enabled = true
+	colors.stderr = term.IsTerminal(int(os.Stderr.Fd()))
+	colors.applyLSCOLORS(os.Getenv("LS_COLORS"))
+	colors.applyMYLSCOLORS(os.Getenv("MYLS_COLORS"))
 }
 
 // colorize adds colours to e's uiName and returns it.
@@ -123,8 +193,12 @@ func colorize(e entry) string {
 		kind = "su"
 	case m&os.ModeType == 0 && m&os.ModeSetgid != 0:
 		kind = "sg"
+	case m.IsRegular() && colors.types["ca"] != "" && fileCapabilities(e.fullPath) != "":
+		kind = "ca"
 	case isExecutable(e):
 		kind = "ex"
+	case m&os.ModeType == 0 && isMultiHardlink(e):
+		kind = "mh"
 	}
 
 	if style := colors.types[kind]; style != "" {
@@ -144,6 +218,130 @@ func colorize(e entry) string {
 	return sgr(colors.types["fi"], e.uiName)
 }
 
+// isMultiHardlink reports whether e has more than one hard link.
+func isMultiHardlink(e entry) bool {
+	n, ok := fileLinks(e.info)
+	return ok && n > 1
+}
+
+// metaStyle returns the $MYLS_COLORS colour sequence for key, or "" if
+// colours are disabled.
+func metaStyle(key string) string {
+	if !colors.enabled {
+		return ""
+	}
+	return colors.meta[key]
+}
+
+// colorMode adds colours to the permission bits and attribute indicator of
+// the ls-style mode string s and returns it.
+func colorMode(s string) string {
+	if !colors.enabled || runtime.GOOS == "windows" || len(s) < 10 {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte(s[0])
+	for i := 1; i < len(s); i++ {
+		var key string
+		switch c := s[i]; {
+		case i > 9:
+			key = "xa"
+		case c == 's' || c == 'S' || c == 't' || c == 'T':
+			key = "sp"
+		case c != '-':
+			key = string("ugw"[(i-1)/3]) + string(c)
+		}
+		b.WriteString(sgr(colors.meta[key], s[i:i+1]))
+	}
+	return b.String()
+}
+
+// colorSize adds colours to s, the formatted size n, according to its
+// magnitude and returns it.
+func colorSize(n int64, s string) string {
+	if !colors.enabled {
+		return s
+	}
+	key := "nt"
+	switch {
+	case n < 1<<10:
+		key = "nb"
+	case n < 1<<20:
+		key = "nk"
+	case n < 1<<30:
+		key = "nm"
+	case n < 1<<40:
+		key = "ng"
+	}
+	return sgr(cmp.Or(colors.meta[key], colors.meta["sn"]), s)
+}
+
+// colorTime adds colours to s, the formatted time t, according to its age
+// and returns it.
+func colorTime(t time.Time, s string) string {
+	if !colors.enabled {
+		return s
+	}
+	key := "da"
+	switch age := now().Sub(t); {
+	case age < 0:
+	case age < time.Hour:
+		key = "dh"
+	case age < day:
+		key = "dd"
+	case age < 7*day:
+		key = "dw"
+	}
+	return sgr(cmp.Or(colors.meta[key], colors.meta["da"]), s)
+}
+
+// colorGit adds colours to the staged and worktree codes of the Git
+// status s and returns it.
+func colorGit(s string) string {
+	if !colors.enabled || len(s) != 2 {
+		return s
+	}
+	conflict := strings.Contains(s, "U") || s == "AA" || s == "DD"
+
+	var b strings.Builder
+	for i := range len(s) {
+		var key string
+		switch c := s[i]; {
+		case conflict:
+			key = "gc"
+		case c == 'A' || c == 'C':
+			key = "ga"
+		case c == 'M':
+			key = "gm"
+		case c == 'D':
+			key = "gd"
+		case c == 'R':
+			key = "gv"
+		case c == 'T':
+			key = "gt"
+		case c == '?':
+			key = "gu"
+		case c == '!':
+			key = "gi"
+		}
+
+		style := colors.meta[key]
+		switch key {
+		case "ga", "gm", "gd", "gv", "gt":
+			// Distinguish staged (first) from worktree (second) changes.
+			extra := colors.meta["sc"]
+			if i == 1 {
+				extra = colors.meta["wc"]
+			}
+			if extra != "" {
+				style = strings.TrimPrefix(style+";"+extra, ";")
+			}
+		}
+		b.WriteString(sgr(style, s[i:i+1]))
+	}
+	return b.String()
+}
+
 // sgr applies style to s and returns it as a valid ANSI escape sequence.
 func sgr(style, s string) string {
 	if style == "" {
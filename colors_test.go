@@ -0,0 +1,40 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestMetaKeysDoNotShadowTypes(t *testing.T) {
+	// $MYLS_COLORS passes keys it does not know on to the $LS_COLORS parser,
+	// so a shared key could never be used to colour file names.
+	for k := range colors.meta {
+		if _, ok := colors.types[k]; ok {
+			t.Errorf("$MYLS_COLORS key %q is also an $LS_COLORS key", k)
+		}
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mode strings are not coloured on Windows")
+	}
+	saved := colors
+	t.Cleanup(func() { colors = saved })
+	colors.enabled = true
+	colors.types = map[string]string{"tw": "", "su": ""}
+	colors.meta = map[string]string{"ur": "", "ww": "", "sp": "", "xa": ""}
+	colors.applyMYLSCOLORS("ur=1:ww=2:sp=3:xa=4:tw=5:su=6")
+
+	if got, want := colors.types["tw"], "5"; got != want {
+		t.Errorf("types[tw] = %q, want %q", got, want)
+	}
+	if got, want := colors.types["su"], "6"; got != want {
+		t.Errorf("types[su] = %q, want %q", got, want)
+	}
+	got := colorMode("-r-sr--rwT@")
+	want := "-" + sgr("1", "r") + "-" + sgr("3", "s") + "r--r" + sgr("2", "w") + sgr("3", "T") + sgr("4", "@")
+	if got != want {
+		t.Errorf("colorMode = %q, want %q", got, want)
+	}
+}
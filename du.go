@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// duSem bounds the number of directory trees measured at once for -du.
+var duSem = make(chan struct{}, runtime.NumCPU())
+
+// fileKey identifies a file on a system, regardless of its name.
+type fileKey struct {
+	dev, ino uint64
+}
+
+// A treeSize is the measured size of a directory tree.
+type treeSize struct {
+	total int64
+	links map[fileKey]int64 // size of each file in the tree with several hard links
+}
+
+// duCache holds the size of every directory tree measured so far, so that
+// -R and -tree measure each tree only once instead of once per listing
+// above it.
+var (
+	duCache   = map[string]treeSize{}
+	duCacheMu sync.Mutex
+)
+
+// attachDirSizes measures the directories in ents concurrently and stores
+// their total sizes in duSize. The parent entry ".." is left unmeasured,
+// since its tree holds the listed directory and often much more.
+func attachDirSizes(ents []entry) {
+	var wg sync.WaitGroup
+	for i := range ents {
+		if !ents[i].info.IsDir() || ents[i].uiName == ".." {
+			continue
+		}
+		wg.Go(func() {
+			duSem <- struct{}{}
+			defer func() { <-duSem }()
+			ents[i].duSize = dirSize(ents[i].fullPath, ents[i].info)
+		})
+	}
+	wg.Wait()
+}
+
+// dirSize returns the total size of the directory tree rooted at path,
+// whose root is described by info. Files with several hard links are
+// counted once per tree, so a file linked into two listed directories adds
+// to both sizes, unlike du given both of them. Unless -cross-mounts is set,
+// directories on other file systems are skipped. Unreadable directories
+// count as empty.
+func dirSize(path string, info os.FileInfo) int64 {
+	dev, hasDev := fileDevice(info)
+	return measureTree(path, info, dev, hasDev).total
+}
+
+// measureTree measures the tree rooted at path, caching the result for
+// it and every directory below it. Subdirectories not on rootDev are
+// skipped unless -cross-mounts is set.
+func measureTree(path string, info os.FileInfo, rootDev uint64, hasDev bool) treeSize {
+	duCacheMu.Lock()
+	ts, ok := duCache[path]
+	duCacheMu.Unlock()
+	if ok {
+		return ts
+	}
+
+	ts.total = sizeOf(info)
+	// addLink records a hard-linked file and reports whether it is new.
+	addLink := func(k fileKey, size int64) bool {
+		if _, ok := ts.links[k]; ok {
+			return false
+		}
+		if ts.links == nil {
+			ts.links = map[fileKey]int64{}
+		}
+		ts.links[k] = size
+		return true
+	}
+
+	ents, _ := os.ReadDir(path)
+	for _, d := range ents {
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		dev, _ := fileDevice(info)
+		if d.IsDir() {
+			if hasDev && dev != rootDev && !opt.crossFS {
+				continue
+			}
+			sub := measureTree(filepath.Join(path, d.Name()), info, rootDev, hasDev)
+			ts.total += sub.total
+			for k, size := range sub.links {
+				if !addLink(k, size) {
+					ts.total -= size
+				}
+			}
+			continue
+		}
+		if n, ok := fileLinks(info); ok && n > 1 {
+			ino, _ := fileInode(info)
+			if !addLink(fileKey{dev, ino}, sizeOf(info)) {
+				continue
+			}
+		}
+		ts.total += sizeOf(info)
+	}
+
+	duCacheMu.Lock()
+	duCache[path] = ts
+	duCacheMu.Unlock()
+	return ts
+}
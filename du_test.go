@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSizeHardLinks(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/x", "b"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f := filepath.Join(root, "a", "f")
+	if err := os.WriteFile(f, make([]byte, 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"a/x/g", "b/h"} {
+		if err := os.Link(f, filepath.Join(root, link)); err != nil {
+			t.Skip("hard links not supported:", err)
+		}
+	}
+
+	size := func(rel string) int64 {
+		path := filepath.Join(root, rel)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dirSize(path, info)
+	}
+	dirOnly := func(rel string) int64 {
+		info, err := os.Stat(filepath.Join(root, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sizeOf(info)
+	}
+
+	// Measure a subtree first so that the larger trees reuse its result.
+	x := size("a/x")
+	if want := dirOnly("a/x") + 1000; x != want {
+		t.Errorf("size of a/x = %d, want %d", x, want)
+	}
+	a := size("a")
+	if want := dirOnly("a") + dirOnly("a/x") + 1000; a != want {
+		t.Errorf("size of a = %d, want %d", a, want)
+	}
+	b := size("b")
+	if want := dirOnly("b") + 1000; b != want {
+		t.Errorf("size of b = %d, want %d", b, want)
+	}
+	all := size(".")
+	if want := dirOnly(".") + dirOnly("a") + dirOnly("a/x") + dirOnly("b") + 1000; all != want {
+		t.Errorf("size of root = %d, want %d", all, want)
+	}
+}
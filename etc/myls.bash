@@ -7,21 +7,75 @@ _myls() {
 	local prev="${COMP_WORDS[COMP_CWORD - 1]}"
 
 	local -a opts=(
-		-h -help
-		-V -version
-		-a
-		-d
-		-l
-		-r
+		-h --help
+		-V --version
+		-a --all
+		-d --directory
+		-l --long
+		-r --reverse
+		-R --recursive
 		-1
-		-dirsfirst
-		-git
-		-sort
+		-n --numeric-uid-gid
+		-Z --context
+		--dirsfirst
+		--git
+		--sort
+		--tree
+		--depth
+		--json
+		--ndjson
+		--time-field
+		--header
+		--columns
+		--xattr
+		--flags
+		--size-style
+		--thousands
+		--du
+		--cross-mounts
+		--summary
+		--reltime
+		--time-style
+		--tz
+		--recent
 	)
 
-	if [[ "$prev" == "-sort" ]]; then
-		COMPREPLY=($(compgen -W "name extension size time git" -- "$cur"))
-	elif [[ "$cur" == -* ]]; then
+	case "$prev" in
+	-sort | --sort)
+		COMPREPLY=($(compgen -W "name extension size time git natural none" -- "$cur"))
+		return
+		;;
+	--depth)
+		COMPREPLY=()
+		return
+		;;
+	--time-field)
+		COMPREPLY=($(compgen -W "mtime atime ctime btime" -- "$cur"))
+		return
+		;;
+	--columns)
+		COMPREPLY=($(compgen -W "inode mode flags links owner group context size time git name" -- "$cur"))
+		return
+		;;
+	--size-style)
+		COMPREPLY=($(compgen -W "binary si bytes blocks" -- "$cur"))
+		return
+		;;
+	--time-style)
+		COMPREPLY=($(compgen -W "full-iso long-iso iso locale relative" -- "$cur"))
+		return
+		;;
+	--tz)
+		COMPREPLY=()
+		return
+		;;
+	--recent)
+		COMPREPLY=()
+		return
+		;;
+	esac
+
+	if [[ "$cur" == -* ]]; then
 		COMPREPLY=($(compgen -W "${opts[*]}" -- "$cur"))
 	else
 		COMPREPLY=($(compgen -f -d -- "$cur"))
@@ -2,13 +2,34 @@
 #
 # Save this file as `myls.fish` in a directory used by fish completions (`$fish_complete_path`).
 
-complete -c myls -o h -o help -d 'show help message and exit'
-complete -c myls -o V -o version -d 'show program\'s version number and exit'
-complete -c myls -o a -d 'do not ignore entries starting with .'
-complete -c myls -o d -d 'list directories themselves, not their contents'
-complete -c myls -o l -d 'use a long listing format'
-complete -c myls -o r -d 'reverse order while sorting'
-complete -c myls -o 1 -d 'display one entry per line'
-complete -c myls -o dirsfirst -d 'show directories above regular files'
-complete -c myls -o git -d 'display git status'
-complete -c myls -o sort -x -k -a "name\t extension\t size\t time\t git\t" -d 'one of: name, extension, size, time, git (default: name)'
+complete -c myls -s h -l help -d 'show help message and exit'
+complete -c myls -s V -l version -d 'show program\'s version number and exit'
+complete -c myls -s a -l all -d 'do not ignore entries starting with .'
+complete -c myls -s d -l directory -d 'list directories themselves, not their contents'
+complete -c myls -s l -l long -d 'use a long listing format'
+complete -c myls -s r -l reverse -d 'reverse order while sorting'
+complete -c myls -s R -l recursive -d 'list subdirectories recursively'
+complete -c myls -s 1 -d 'display one entry per line'
+complete -c myls -s n -l numeric-uid-gid -d 'list numeric user and group IDs'
+complete -c myls -s Z -l context -d 'print the SELinux security context of each entry'
+complete -c myls -l dirsfirst -d 'show directories above regular files'
+complete -c myls -l git -d 'display git status'
+complete -c myls -l sort -x -k -a "name\t extension\t size\t time\t git\t natural\t none\t" -d 'comma-separated list of sort keys, prefix with - to reverse (default: name)'
+complete -c myls -l tree -d 'display directories as a tree'
+complete -c myls -l depth -x -d 'limit -R and --tree to N levels of depth (default: unlimited)'
+complete -c myls -l json -d 'print entries and errors as JSON, grouped by directory'
+complete -c myls -l ndjson -d 'print one JSON object per entry or error'
+complete -c myls -l time-field -x -k -a "mtime\t atime\t ctime\t btime\t" -d 'timestamp to show and sort by (default: mtime)'
+complete -c myls -l header -d 'print a header row above long listings'
+complete -c myls -l columns -x -k -a "inode\t mode\t flags\t links\t owner\t group\t context\t size\t time\t git\t name\t" -d 'comma-separated list of long listing columns (default: mode,owner,group,size,time,git,name)'
+complete -c myls -l xattr -d 'list extended attribute names and sizes in long listings'
+complete -c myls -l flags -d 'show inode flags like lsattr in long listings'
+complete -c myls -l size-style -x -k -a "binary\t si\t bytes\t blocks\t" -d 'how to show and sort file sizes'
+complete -c myls -l thousands -d 'group the digits of exact sizes with commas'
+complete -c myls -l du -d 'show and sort directories by the total size of their contents'
+complete -c myls -l cross-mounts -d 'with --du, include directories on other file systems'
+complete -c myls -l summary -d 'print the number of entries by type and their total size'
+complete -c myls -l reltime -d 'show times relative to now'
+complete -c myls -l time-style -x -k -a "full-iso\t long-iso\t iso\t locale\t relative\t" -d 'time format'
+complete -c myls -l tz -x -d 'show times in a time zone'
+complete -c myls -l recent -x -d 'window for the recent time format'
@@ -7,21 +7,53 @@ using namespace System.Management.Automation
 Register-ArgumentCompleter -Native -CommandName 'myls' -ScriptBlock {
 	param($wordToComplete, $commandAst, $cursorPosition)
 
-	$sortValues = @('name', 'extension', 'size', 'time', 'git')
+	$sortValues = @('name', 'extension', 'size', 'time', 'git', 'natural', 'none')
+	$timeFieldValues = @('mtime', 'atime', 'ctime', 'btime')
+	$columnsValues = @('inode', 'mode', 'flags', 'links', 'owner', 'group', 'context', 'size', 'time', 'git', 'name')
+	$sizeStyleValues = @('binary', 'si', 'bytes', 'blocks')
+	$timeStyleValues = @('full-iso', 'long-iso', 'iso', 'locale', 'relative')
 
 	$completions = @(
-		[CompletionResult]::new('-h',         '-h',         [CompletionResultType]::ParameterName, 'show help message and exit')
-		[CompletionResult]::new('-help',      '-help',      [CompletionResultType]::ParameterName, 'show help message and exit')
-		[CompletionResult]::new('-V',         '-V',         [CompletionResultType]::ParameterName, "show program's version number and exit")
-		[CompletionResult]::new('-version',   '-version',   [CompletionResultType]::ParameterName, "show program's version number and exit")
-		[CompletionResult]::new('-a',         '-a',         [CompletionResultType]::ParameterName, 'do not ignore entries starting with .')
-		[CompletionResult]::new('-d',         '-d',         [CompletionResultType]::ParameterName, 'list directories themselves, not their contents')
-		[CompletionResult]::new('-l',         '-l',         [CompletionResultType]::ParameterName, 'use a long listing format')
-		[CompletionResult]::new('-r',         '-r',         [CompletionResultType]::ParameterName, 'reverse order while sorting')
-		[CompletionResult]::new('-1',         '-1',         [CompletionResultType]::ParameterName, 'display one entry per line')
-		[CompletionResult]::new('-dirsfirst', '-dirsfirst', [CompletionResultType]::ParameterName, 'show directories above regular files')
-		[CompletionResult]::new('-git',       '-git',       [CompletionResultType]::ParameterName, 'display git status')
-		[CompletionResult]::new('-sort ',     '-sort',      [CompletionResultType]::ParameterName, 'one of: name, extension, size, time, git (default: name)')
+		[CompletionResult]::new('-h',                '-h',                [CompletionResultType]::ParameterName, 'show help message and exit')
+		[CompletionResult]::new('--help',            '--help',            [CompletionResultType]::ParameterName, 'show help message and exit')
+		[CompletionResult]::new('-V',                '-V',                [CompletionResultType]::ParameterName, "show program's version number and exit")
+		[CompletionResult]::new('--version',         '--version',         [CompletionResultType]::ParameterName, "show program's version number and exit")
+		[CompletionResult]::new('-a',                '-a',                [CompletionResultType]::ParameterName, 'do not ignore entries starting with .')
+		[CompletionResult]::new('--all',             '--all',             [CompletionResultType]::ParameterName, 'do not ignore entries starting with .')
+		[CompletionResult]::new('-d',                '-d',                [CompletionResultType]::ParameterName, 'list directories themselves, not their contents')
+		[CompletionResult]::new('--directory',       '--directory',       [CompletionResultType]::ParameterName, 'list directories themselves, not their contents')
+		[CompletionResult]::new('-l',                '-l',                [CompletionResultType]::ParameterName, 'use a long listing format')
+		[CompletionResult]::new('--long',            '--long',            [CompletionResultType]::ParameterName, 'use a long listing format')
+		[CompletionResult]::new('-r',                '-r',                [CompletionResultType]::ParameterName, 'reverse order while sorting')
+		[CompletionResult]::new('--reverse',         '--reverse',         [CompletionResultType]::ParameterName, 'reverse order while sorting')
+		[CompletionResult]::new('-R',                '-R',                [CompletionResultType]::ParameterName, 'list subdirectories recursively')
+		[CompletionResult]::new('--recursive',       '--recursive',       [CompletionResultType]::ParameterName, 'list subdirectories recursively')
+		[CompletionResult]::new('-1',                '-1',                [CompletionResultType]::ParameterName, 'display one entry per line')
+		[CompletionResult]::new('-n',                '-n',                [CompletionResultType]::ParameterName, 'list numeric user and group IDs')
+		[CompletionResult]::new('--numeric-uid-gid', '--numeric-uid-gid', [CompletionResultType]::ParameterName, 'list numeric user and group IDs')
+		[CompletionResult]::new('-Z',                '-Z',                [CompletionResultType]::ParameterName, 'print the SELinux security context of each entry')
+		[CompletionResult]::new('--context',         '--context',         [CompletionResultType]::ParameterName, 'print the SELinux security context of each entry')
+		[CompletionResult]::new('--dirsfirst',       '--dirsfirst',       [CompletionResultType]::ParameterName, 'show directories above regular files')
+		[CompletionResult]::new('--git',             '--git',             [CompletionResultType]::ParameterName, 'display git status')
+		[CompletionResult]::new('--sort ',           '--sort',            [CompletionResultType]::ParameterName, 'comma-separated list of sort keys, prefix with - to reverse (default: name)')
+		[CompletionResult]::new('--tree',            '--tree',            [CompletionResultType]::ParameterName, 'display directories as a tree')
+		[CompletionResult]::new('--depth ',          '--depth',           [CompletionResultType]::ParameterName, 'limit -R and --tree to N levels of depth (default: unlimited)')
+		[CompletionResult]::new('--json',            '--json',            [CompletionResultType]::ParameterName, 'print entries and errors as JSON, grouped by directory')
+		[CompletionResult]::new('--ndjson',          '--ndjson',          [CompletionResultType]::ParameterName, 'print one JSON object per entry or error')
+		[CompletionResult]::new('--time-field ',     '--time-field',      [CompletionResultType]::ParameterName, 'timestamp to show and sort by (default: mtime)')
+		[CompletionResult]::new('--header',          '--header',          [CompletionResultType]::ParameterName, 'print a header row above long listings')
+		[CompletionResult]::new('--columns ',        '--columns',         [CompletionResultType]::ParameterName, 'comma-separated list of long listing columns (default: mode,owner,group,size,time,git,name)')
+		[CompletionResult]::new('--xattr',           '--xattr',           [CompletionResultType]::ParameterName, 'list extended attribute names and sizes in long listings')
+		[CompletionResult]::new('--flags',           '--flags',           [CompletionResultType]::ParameterName, 'show inode flags like lsattr in long listings')
+		[CompletionResult]::new('--size-style ',     '--size-style',      [CompletionResultType]::ParameterName, 'how to show and sort file sizes')
+		[CompletionResult]::new('--thousands',       '--thousands',       [CompletionResultType]::ParameterName, 'group the digits of exact sizes with commas')
+		[CompletionResult]::new('--du',              '--du',              [CompletionResultType]::ParameterName, 'show and sort directories by the total size of their contents')
+		[CompletionResult]::new('--cross-mounts',    '--cross-mounts',    [CompletionResultType]::ParameterName, 'with --du, include directories on other file systems')
+		[CompletionResult]::new('--summary',         '--summary',         [CompletionResultType]::ParameterName, 'print the number of entries by type and their total size')
+		[CompletionResult]::new('--reltime',         '--reltime',         [CompletionResultType]::ParameterName, 'show times relative to now')
+		[CompletionResult]::new('--time-style ',     '--time-style',      [CompletionResultType]::ParameterName, 'time format')
+		[CompletionResult]::new('--tz ',             '--tz',              [CompletionResultType]::ParameterName, 'show times in a time zone')
+		[CompletionResult]::new('--recent ',         '--recent',          [CompletionResultType]::ParameterName, 'window for the recent time format')
 	)
 
 	if ($wordToComplete.StartsWith('-')) {
@@ -34,10 +66,16 @@ Register-ArgumentCompleter -Native -CommandName 'myls' -ScriptBlock {
 		Where-Object { $_.Extent.EndOffset -lt $cursorPosition } |
 		Select-Object -Last 1
 
-	if ($previousElement.Extent.Text -eq '-sort') {
-		$sortValues.Where{ $_ -like "$wordToComplete*" } |
-			ForEach-Object {
-				[CompletionResult]::new($_, $_, [CompletionResultType]::ParameterValue, $_)
-			}
+	$values = switch ($previousElement.Extent.Text) {
+		'-sort'        { $sortValues }
+		'--sort'       { $sortValues }
+		'--time-field' { $timeFieldValues }
+		'--columns'    { $columnsValues }
+		'--size-style' { $sizeStyleValues }
+		'--time-style' { $timeStyleValues }
 	}
+	$values.Where{ $_ -like "$wordToComplete*" } |
+		ForEach-Object {
+			[CompletionResult]::new($_, $_, [CompletionResultType]::ParameterValue, $_)
+		}
 }
@@ -0,0 +1,35 @@
+//go:build darwin || freebsd || netbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statTime returns the timestamp of the file at path selected by field,
+// or the zero time if it is unavailable. info must describe the file.
+func statTime(path string, info os.FileInfo, field timeField) time.Time {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return time.Time{}
+	}
+
+	switch field {
+	case modTime:
+		return info.ModTime()
+	case accessTime:
+		return time.Unix(st.Atimespec.Unix())
+	case changeTime:
+		return time.Unix(st.Ctimespec.Unix())
+	case birthTime:
+		// Filesystems without birth times report -1 or 0.
+		if st.Birthtimespec.Sec <= 0 {
+			return time.Time{}
+		}
+		return time.Unix(st.Birthtimespec.Unix())
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// statTime returns the timestamp of the file at path selected by field,
+// or the zero time if it is unavailable. info must describe the file.
+// Birth times are read with statx(2), which not every filesystem supports.
+func statTime(path string, info os.FileInfo, field timeField) time.Time {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return time.Time{}
+	}
+
+	switch field {
+	case modTime:
+		return info.ModTime()
+	case accessTime:
+		return time.Unix(st.Atim.Unix())
+	case changeTime:
+		return time.Unix(st.Ctim.Unix())
+	case birthTime:
+		var stx unix.Statx_t
+		err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx)
+		if err != nil || stx.Mask&unix.STATX_BTIME == 0 {
+			return time.Time{}
+		}
+		return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	default:
+		return time.Time{}
+	}
+}
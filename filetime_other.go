@@ -0,0 +1,18 @@
+//go:build !unix && !windows
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// statTime returns the timestamp of the file at path selected by field,
+// or the zero time if it is unavailable. info must describe the file.
+// Only modification times are available on these systems.
+func statTime(path string, info os.FileInfo, field timeField) time.Time {
+	if field == modTime {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
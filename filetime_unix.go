@@ -0,0 +1,30 @@
+//go:build unix && !linux && !darwin && !freebsd && !netbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statTime returns the timestamp of the file at path selected by field,
+// or the zero time if it is unavailable. info must describe the file.
+// Birth times are not available on these systems.
+func statTime(path string, info os.FileInfo, field timeField) time.Time {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return time.Time{}
+	}
+
+	switch field {
+	case modTime:
+		return info.ModTime()
+	case accessTime:
+		return time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec))
+	case changeTime:
+		return time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec))
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statTime returns the timestamp of the file at path selected by field,
+// or the zero time if it is unavailable. info must describe the file.
+// Windows does not record change times.
+func statTime(path string, info os.FileInfo, field timeField) time.Time {
+	sys, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok || sys == nil {
+		return time.Time{}
+	}
+
+	switch field {
+	case modTime:
+		return info.ModTime()
+	case accessTime:
+		return time.Unix(0, sys.LastAccessTime.Nanoseconds())
+	case birthTime:
+		return time.Unix(0, sys.CreationTime.Nanoseconds())
+	default:
+		return time.Time{}
+	}
+}
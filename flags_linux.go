@@ -0,0 +1,62 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// inodeFlagLetters maps inode flags to the letters used by lsattr(1),
+// in lsattr's order.
+var inodeFlagLetters = []struct {
+	flag   uint32
+	letter byte
+}{
+	{0x00000001, 's'}, // FS_SECRM_FL
+	{0x00000002, 'u'}, // FS_UNRM_FL
+	{0x00000008, 'S'}, // FS_SYNC_FL
+	{0x00010000, 'D'}, // FS_DIRSYNC_FL
+	{0x00000010, 'i'}, // FS_IMMUTABLE_FL
+	{0x00000020, 'a'}, // FS_APPEND_FL
+	{0x00000040, 'd'}, // FS_NODUMP_FL
+	{0x00000080, 'A'}, // FS_NOATIME_FL
+	{0x00000004, 'c'}, // FS_COMPR_FL
+	{0x00000800, 'E'}, // FS_ENCRYPT_FL
+	{0x00004000, 'j'}, // FS_JOURNAL_DATA_FL
+	{0x00001000, 'I'}, // FS_INDEX_FL
+	{0x00008000, 't'}, // FS_NOTAIL_FL
+	{0x00020000, 'T'}, // FS_TOPDIR_FL
+	{0x00080000, 'e'}, // FS_EXTENT_FL
+	{0x00800000, 'C'}, // FS_NOCOW_FL
+	{0x02000000, 'x'}, // FS_DAX_FL
+	{0x40000000, 'F'}, // FS_CASEFOLD_FL
+	{0x10000000, 'N'}, // FS_INLINE_DATA_FL
+	{0x20000000, 'P'}, // FS_PROJINHERIT_FL
+	{0x00100000, 'V'}, // FS_VERITY_FL
+	{0x00000400, 'm'}, // FS_NOCOMP_FL
+}
+
+// inodeFlags returns e's inode flags as set by chattr(1), as a string of
+// lsattr letters (e.g. "ia"), "-" if none are set, or "" if they cannot be
+// read. Like lsattr, it only considers regular files and directories.
+func inodeFlags(e entry) string {
+	if m := e.info.Mode(); !m.IsRegular() && !m.IsDir() {
+		return ""
+	}
+	fd, err := unix.Open(e.fullPath, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return ""
+	}
+	defer unix.Close(fd)
+	flags, err := unix.IoctlGetUint32(fd, unix.FS_IOC_GETFLAGS)
+	if err != nil {
+		return ""
+	}
+
+	var b []byte
+	for _, f := range inodeFlagLetters {
+		if flags&f.flag != 0 {
+			b = append(b, f.letter)
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
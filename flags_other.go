@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// inodeFlags returns e's inode flags, which are only supported on Linux.
+func inodeFlags(e entry) string {
+	return ""
+}
@@ -39,12 +39,11 @@ func attachGitToFiles(ents []entry) {
 			continue
 		}
 		showGit = true
-		if signs, ok := stats[e.fullPath]; ok {
-			e.gitStatus = strings.ReplaceAll(signs, " ", "-")
-		}
+		e.gitStatus = gitStatusOf(stats, e.fullPath)
 	}
 
-	// Only add placeholders if any Git status is present.
+	// Only add placeholders for files outside a repository if any Git
+	// status is present.
 	if !showGit {
 		return
 	}
@@ -63,13 +62,17 @@ func attachGitToDir(dir string, ents []entry) {
 	}
 
 	for i := range ents {
-		e := &ents[i]
-		if signs, ok := stats[e.fullPath]; ok {
-			e.gitStatus = strings.ReplaceAll(signs, " ", "-")
-		} else {
-			e.gitStatus = "--"
-		}
+		ents[i].gitStatus = gitStatusOf(stats, ents[i].fullPath)
+	}
+}
+
+// gitStatusOf returns the displayed Git status of path given the status
+// codes of its repository.
+func gitStatusOf(stats map[string]string, path string) string {
+	if signs, ok := stats[path]; ok {
+		return strings.ReplaceAll(signs, " ", "-")
 	}
+	return "--"
 }
 
 // gitPriority ranks Git status codes by significance (higher wins).
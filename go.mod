@@ -2,6 +2,7 @@ module github.com/CatsDeservePets/myls
 
 go 1.25.3
 
-require golang.org/x/term v0.37.0
-
-require golang.org/x/sys v0.38.0 // indirect
+require (
+	golang.org/x/sys v0.38.0
+	golang.org/x/term v0.37.0
+)
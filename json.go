@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ndjsonBatch is the number of names read from a directory at once when
+// streaming -ndjson output.
+const ndjsonBatch = 1024
+
+// A jsonEntry is the JSON representation of an [entry].
+type jsonEntry struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Mode       string    `json:"mode"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mtime"`
+	LinkTarget string    `json:"link_target,omitempty"`
+	LinkMode   string    `json:"link_mode,omitempty"`
+	DirCount   *int      `json:"dir_count,omitempty"`
+	GitStatus  string    `json:"git_status,omitempty"`
+}
+
+// A jsonDir is the JSON representation of a [listing].
+type jsonDir struct {
+	Path    string      `json:"path"`
+	Name    string      `json:"name"`
+	Entries []jsonEntry `json:"entries"`
+	Errors  []string    `json:"errors,omitempty"`
+}
+
+// jsonOutput is the document written by -json.
+type jsonOutput struct {
+	Files       []jsonEntry `json:"files"`
+	Directories []jsonDir   `json:"directories"`
+	Errors      []string    `json:"errors,omitempty"`
+}
+
+// printJSON prints files and the directory listings as a single JSON
+// document. With -R, the listings of all subdirectories are included.
+// errs are errors not tied to any listing and are reported in-band.
+func printJSON(files []entry, listings []listing, errs []error) {
+	out := jsonOutput{
+		Files:       toJSONEntries(files),
+		Directories: []jsonDir{},
+		Errors:      errorStrings(errs),
+	}
+	for _, l := range listings {
+		out.Directories = append(out.Directories, toJSONDir(l))
+		if opt.recursive || opt.tree {
+			err := walkSubdirs(l, func(l listing) {
+				out.Directories = append(out.Directories, toJSONDir(l))
+			})
+			if err != nil {
+				out.Errors = append(out.Errors, err.Error())
+			}
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		showError(err)
+	}
+}
+
+// toJSONDir converts l to its JSON representation.
+func toJSONDir(l listing) jsonDir {
+	return jsonDir{
+		Path:    l.dir.fullPath,
+		Name:    l.dir.uiName,
+		Entries: toJSONEntries(l.ents),
+		Errors:  errorStrings(l.errs),
+	}
+}
+
+// toJSONEntries converts ents to their JSON representation.
+func toJSONEntries(ents []entry) []jsonEntry {
+	out := make([]jsonEntry, 0, len(ents))
+	for _, e := range ents {
+		out = append(out, toJSONEntry(e, true))
+	}
+	return out
+}
+
+// toJSONEntry converts e to its JSON representation. Unless countDirs is
+// set, directories whose items have not been counted yet are left without
+// a dir_count.
+func toJSONEntry(e entry, countDirs bool) jsonEntry {
+	j := jsonEntry{
+		Path:       e.fullPath,
+		Name:       e.uiName,
+		Type:       fileType(e.info.Mode()),
+		Mode:       mode(e),
+		Size:       e.info.Size(),
+		ModTime:    e.info.ModTime().In(opt.location),
+		LinkTarget: e.linkTarget,
+		GitStatus:  e.gitStatus,
+	}
+
+	switch e.linkMode {
+	case working:
+		j.LinkMode = "working"
+	case orphan:
+		j.LinkMode = "orphan"
+	}
+
+	if e.dirLike {
+		n := e.dirCount
+		if n < 0 && !countDirs {
+			return j
+		}
+		if n < 0 {
+			var err error
+			if n, err = countDirEntries(e.fullPath); err != nil {
+				return j
+			}
+		}
+		j.DirCount = &n
+	}
+	return j
+}
+
+// An ndjsonRecord is a line of -ndjson output: either an entry of the
+// directory Dir, or an error encountered while reading it.
+type ndjsonRecord struct {
+	Dir string `json:"dir,omitempty"`
+	*jsonEntry
+	Error string `json:"error,omitempty"`
+}
+
+// An ndjsonWriter writes -ndjson records to standard output.
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// printNDJSON prints files and the contents of dirs as newline-delimited
+// JSON, one object per entry or error. With -sort none, entries are written
+// as soon as they have been read, so output starts immediately and memory
+// use stays flat even for very large directories. Otherwise each directory
+// is read in full and sorted first.
+func printNDJSON(files, dirs []entry, errs []error) {
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	nw := &ndjsonWriter{w: w, enc: json.NewEncoder(w)}
+
+	for _, err := range errs {
+		nw.writeError("", err)
+	}
+	for _, e := range files {
+		nw.writeEntry("", e, true)
+	}
+	w.Flush()
+
+	for _, d := range dirs {
+		if opt.sort.isUnsorted() {
+			info, err := os.Stat(d.fullPath)
+			if err != nil {
+				nw.writeError(d.uiName, err)
+				continue
+			}
+			nw.streamDirs(d, []os.FileInfo{info}, 1)
+			continue
+		}
+
+		l := readDirEntries(d)
+		nw.writeListing(l)
+		if opt.recursive || opt.tree {
+			if err := walkSubdirs(l, nw.writeListing); err != nil {
+				nw.writeError(l.dir.uiName, err)
+			}
+		}
+	}
+}
+
+// writeListing writes the errors and entries of l.
+func (nw *ndjsonWriter) writeListing(l listing) {
+	for _, err := range l.errs {
+		nw.writeError(l.dir.uiName, err)
+	}
+	for _, e := range l.ents {
+		nw.writeEntry(l.dir.uiName, e, true)
+	}
+	nw.w.Flush()
+}
+
+// streamDirs streams d and, with -R, its subdirectories, depth first.
+// d's entries are at the given level of depth below the listed directory;
+// ancestors is as for [readSubdirs].
+func (nw *ndjsonWriter) streamDirs(d entry, ancestors []os.FileInfo, level int) {
+	subdirs := nw.streamDir(d)
+	if !opt.recursive && !opt.tree || opt.depth > 0 && level >= opt.depth {
+		return
+	}
+
+	for _, s := range subdirs {
+		s.uiName = subdirName(d.uiName, s.uiName)
+		s, info, err := statSubdir(s, ancestors)
+		if err != nil {
+			nw.writeError(s.uiName, err)
+			continue
+		}
+		nw.streamDirs(s, append(slices.Clip(ancestors), info), level+1)
+	}
+}
+
+// streamDir writes the entries of d in directory order as soon as they
+// have been read and returns those that are directories.
+func (nw *ndjsonWriter) streamDir(d entry) (subdirs []entry) {
+	f, err := os.Open(d.fullPath)
+	if err != nil {
+		nw.writeError(d.uiName, err)
+		return nil
+	}
+	defer f.Close()
+
+	var stats map[string]string
+	if gitEnabled() {
+		stats = gitStatusesForDir(d.fullPath)
+	}
+	write := func(e entry) {
+		if stats != nil {
+			e.gitStatus = gitStatusOf(stats, e.fullPath)
+		}
+		// Counting items would read every subdirectory twice and
+		// cache the result, so streamed entries go without.
+		nw.writeEntry(d.uiName, e, false)
+	}
+
+	if opt.all {
+		dots, err := dotEntries(d)
+		for _, e := range dots {
+			write(e)
+		}
+		if err != nil {
+			nw.writeError(d.uiName, err)
+		}
+	}
+
+	for {
+		names, err := f.Readdirnames(ndjsonBatch)
+		for _, name := range names {
+			e, err := newEntry(filepath.Join(d.fullPath, name), name)
+			if err != nil {
+				nw.writeError(d.uiName, err)
+				continue
+			}
+			if !opt.all && isHidden(e) {
+				continue
+			}
+			write(e)
+			if e.dirLike {
+				subdirs = append(subdirs, e)
+			}
+		}
+		nw.w.Flush()
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			nw.writeError(d.uiName, err)
+			break
+		}
+	}
+	return subdirs
+}
+
+// writeEntry writes e as an entry of dir. countDirs is as for
+// [toJSONEntry].
+func (nw *ndjsonWriter) writeEntry(dir string, e entry, countDirs bool) {
+	j := toJSONEntry(e, countDirs)
+	nw.encode(ndjsonRecord{Dir: dir, jsonEntry: &j})
+}
+
+// writeError writes err as an error encountered while reading dir.
+func (nw *ndjsonWriter) writeError(dir string, err error) {
+	nw.encode(ndjsonRecord{Dir: dir, Error: err.Error()})
+}
+
+// encode writes rec as a single line.
+func (nw *ndjsonWriter) encode(rec ndjsonRecord) {
+	if err := nw.enc.Encode(rec); err != nil {
+		showError(err)
+		os.Exit(1)
+	}
+}
+
+// fileType returns a name for the type of file described by m.
+func fileType(m os.FileMode) string {
+	switch {
+	case m&os.ModeSymlink != 0:
+		return "symlink"
+	case m&os.ModeDir != 0:
+		return "directory"
+	case m&os.ModeNamedPipe != 0:
+		return "fifo"
+	case m&os.ModeSocket != 0:
+		return "socket"
+	case m&os.ModeCharDevice != 0:
+		return "char_device"
+	case m&os.ModeDevice != 0:
+		return "block_device"
+	case m&os.ModeType == 0:
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+// errorStrings returns the messages of errs.
+func errorStrings(errs []error) []string {
+	var out []string
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
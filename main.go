@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // tabWidth is the tab stop width in spaces.
@@ -34,9 +35,12 @@ type entry struct {
 	linkTarget string      // symlink target
 	linkMode   linkMode    // symlink-related information (required by $LS_COLORS)
 	info       os.FileInfo // file metadata
-	gitStatus  string      // Git status (long mode only)
-	dirCount   int         // number of items inside (long mode only)
+	timestamp  time.Time   // time selected by -time-field (zero if unavailable)
+	gitStatus  string      // Git status (long and JSON modes only)
+	dirCount   int         // number of items inside (long and JSON modes only)
+	duSize     int64       // total size of the directory tree (-du only)
 	dirLike    bool        // whether entry is a directory or points to one
+	indent     string      // connectors drawn before the name (tree mode only)
 }
 
 func newEntry(path, name string) (entry, error) {
@@ -51,7 +55,9 @@ func newEntry(path, name string) (entry, error) {
 		sortName: strings.ToLower(name),
 		info:     info,
 		dirCount: -1,
+		duSize:   -1,
 	}
+	e.timestamp = statTime(path, info, opt.timeField)
 
 	if info.IsDir() {
 		e.dirLike = true
@@ -73,40 +79,44 @@ func newEntry(path, name string) (entry, error) {
 	return e, nil
 }
 
-// sortBy controls the primary sort key.
-type sortBy int
+// A sortField is an entry attribute that entries can be sorted by.
+type sortField int
 
 const (
-	name sortBy = iota
+	name sortField = iota
 	extension
 	size
 	mtime
 	git
-	// TODO: Natural sorting
+	natural
+	unsorted
 )
 
-// Set implements the [flag.Value] interface.
-func (s *sortBy) Set(val string) error {
-	switch val {
+// parseSortField returns the sort field called word.
+func parseSortField(word string) (sortField, bool) {
+	switch word {
 	case "name":
-		*s = name
+		return name, true
 	case "ext", "extension":
-		*s = extension
+		return extension, true
 	case "size":
-		*s = size
+		return size, true
 	case "time", "mtime":
-		*s = mtime
+		return mtime, true
 	case "git":
-		*s = git
+		return git, true
+	case "natural":
+		return natural, true
+	case "none":
+		return unsorted, true
 	default:
-		return errors.New("must be name, extension, size, time, or git")
+		return 0, false
 	}
-	return nil
 }
 
-// String implements the [flag.Value] interface.
-func (s sortBy) String() string {
-	switch s {
+// String returns the name of f as accepted by -sort.
+func (f sortField) String() string {
+	switch f {
 	case name:
 		return "name"
 	case extension:
@@ -117,6 +127,159 @@ func (s sortBy) String() string {
 		return "time"
 	case git:
 		return "git"
+	case natural:
+		return "natural"
+	case unsorted:
+		return "none"
+	default:
+		return ""
+	}
+}
+
+// A sortKey is a sort field together with its direction.
+type sortKey struct {
+	field   sortField
+	reverse bool
+}
+
+// sortBy is the list of sort keys, most significant first.
+// The zero value sorts by name.
+type sortBy []sortKey
+
+// Set implements the [flag.Value] interface.
+// val is a comma-separated list of sort fields, each of which may be
+// prefixed with '-' to reverse its order (e.g. "git,-size,name").
+func (s *sortBy) Set(val string) error {
+	var keys sortBy
+	for word := range strings.SplitSeq(val, ",") {
+		word, reverse := strings.CutPrefix(strings.TrimSpace(word), "-")
+		field, ok := parseSortField(word)
+		if !ok {
+			return errors.New("must be a comma-separated list of name, extension, size, time, git, natural, or none")
+		}
+		keys = append(keys, sortKey{field, reverse})
+	}
+	if len(keys) > 1 && slices.ContainsFunc(keys, func(k sortKey) bool {
+		return k.field == unsorted
+	}) {
+		return errors.New("none cannot be combined with other keys")
+	}
+	*s = keys
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (s sortBy) String() string {
+	words := make([]string, len(s))
+	for i, k := range s {
+		words[i] = k.field.String()
+		if k.reverse {
+			words[i] = "-" + words[i]
+		}
+	}
+	return strings.Join(words, ",")
+}
+
+// isUnsorted reports whether s disables sorting altogether.
+func (s sortBy) isUnsorted() bool {
+	return len(s) == 1 && s[0].field == unsorted
+}
+
+// A timeField selects which timestamp of a file is shown and sorted on.
+type timeField int
+
+const (
+	modTime timeField = iota
+	accessTime
+	changeTime
+	birthTime
+)
+
+// Set implements the [flag.Value] interface.
+func (f *timeField) Set(val string) error {
+	switch val {
+	case "mtime":
+		*f = modTime
+	case "atime":
+		*f = accessTime
+	case "ctime":
+		*f = changeTime
+	case "btime":
+		*f = birthTime
+	default:
+		return errors.New("must be mtime, atime, ctime, or btime")
+	}
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (f timeField) String() string {
+	switch f {
+	case modTime:
+		return "mtime"
+	case accessTime:
+		return "atime"
+	case changeTime:
+		return "ctime"
+	case birthTime:
+		return "btime"
+	default:
+		return ""
+	}
+}
+
+// label returns the column header for timestamps of kind f.
+func (f timeField) label() string {
+	switch f {
+	case accessTime:
+		return "Accessed"
+	case changeTime:
+		return "Changed"
+	case birthTime:
+		return "Created"
+	default:
+		return "Modified"
+	}
+}
+
+// A sizeStyle selects how file sizes are shown and which size is sorted on.
+type sizeStyle int
+
+const (
+	binarySize sizeStyle = iota // 1024-based units
+	siSize                      // 1000-based units
+	byteSize                    // exact byte counts
+	blockSize                   // allocated disk space in 1024-based units
+)
+
+// Set implements the [flag.Value] interface.
+func (s *sizeStyle) Set(val string) error {
+	switch val {
+	case "binary":
+		*s = binarySize
+	case "si":
+		*s = siSize
+	case "bytes":
+		*s = byteSize
+	case "blocks":
+		*s = blockSize
+	default:
+		return errors.New("must be binary, si, bytes, or blocks")
+	}
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (s sizeStyle) String() string {
+	switch s {
+	case binarySize:
+		return "binary"
+	case siSize:
+		return "si"
+	case byteSize:
+		return "bytes"
+	case blockSize:
+		return "blocks"
 	default:
 		return ""
 	}
@@ -125,7 +288,7 @@ func (s sortBy) String() string {
 var (
 	progName   = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
 	homeDir, _ = os.UserHomeDir()
-	currYear   = time.Now().Year()
+	now        = time.Now // current time, replaceable for deterministic output
 	dirCounts  = map[string]int{}
 )
 
@@ -133,44 +296,196 @@ func main() {
 	initOptions()
 	initColors()
 
-	files, dirs := collectEntries(opt.args)
+	files, dirs, errs := collectEntries(opt.args)
 	if len(dirs) == 0 && len(files) == 0 {
+		switch {
+		case opt.ndjson:
+			printNDJSON(nil, nil, errs)
+		case opt.json:
+			printJSON(nil, nil, errs)
+		default:
+			showErrors(errs)
+		}
 		os.Exit(1)
 	}
-	showDirHeader := len(files) > 0 || len(dirs) > 1
+	showDirHeader := len(files) > 0 || len(dirs) > 1 || opt.recursive
 
-	if opt.long && opt.git {
+	if gitEnabled() {
 		attachGitToFiles(files)
 	}
+	if opt.du {
+		attachDirSizes(files)
+	}
 	sortEntries(files)
-	printEntries(files)
 	sortEntries(dirs)
 
-	dirEntries := make([][]entry, len(dirs))
+	if opt.ndjson {
+		printNDJSON(files, dirs, errs)
+		return
+	}
+
+	listings := make([]listing, len(dirs))
 	var wg sync.WaitGroup
 	for i, d := range dirs {
 		wg.Go(func() {
-			dirEntries[i] = readDirEntries(d)
+			listings[i] = readDirEntries(d)
 		})
 	}
 	wg.Wait()
 
-	for i, d := range dirs {
+	if opt.json {
+		printJSON(files, listings, errs)
+		return
+	}
+
+	showErrors(errs)
+	printEntries(files)
+	if opt.summary && len(files) > 0 {
+		printSummary(files)
+	}
+	for i, l := range listings {
 		if i > 0 || len(files) > 0 {
 			// Separate directory listing from previous output.
 			fmt.Println()
 		}
+		if opt.tree {
+			printTree(l)
+			continue
+		}
 		if showDirHeader {
 			// If output has multiple sections, label directory
 			// using the user-supplied path (abbreviated with ~).
-			fmt.Print(tildePath(d.uiName), ":\n")
+			printDirHeader(l.dir)
 		}
-		printEntries(dirEntries[i])
+		printListing(l)
+		if opt.recursive {
+			err := walkSubdirs(l, func(l listing) {
+				fmt.Println()
+				printDirHeader(l.dir)
+				printListing(l)
+			})
+			if err != nil {
+				showError(err)
+			}
+		}
+	}
+
+	if opt.summary && len(files)+len(dirs) > 1 {
+		fmt.Println()
+		fmt.Println("grand total", grandTotal)
 	}
 }
 
+// gitEnabled reports whether Git status should be looked up for entries.
+func gitEnabled() bool {
+	return opt.git && (opt.long || opt.json || opt.ndjson)
+}
+
+// walkSubdirs calls fn with the listing of every subdirectory below l,
+// recursively and depth first. It returns an error if l's directory cannot
+// be stat'ed.
+func walkSubdirs(l listing, fn func(listing)) error {
+	info, err := os.Stat(l.dir.fullPath)
+	if err != nil {
+		return err
+	}
+	walkLevel(l, []os.FileInfo{info}, 1, fn)
+	return nil
+}
+
+// walkLevel calls fn for the subdirectories of l and descends into them.
+// l's entries are at the given level of depth below the listed directory;
+// ancestors is as for [readSubdirs].
+func walkLevel(l listing, ancestors []os.FileInfo, level int, fn func(listing)) {
+	if opt.depth > 0 && level >= opt.depth {
+		return
+	}
+
+	var subdirs []entry
+	for _, e := range l.ents {
+		if !e.dirLike || isDotEntry(e) {
+			continue
+		}
+		e.uiName = subdirName(l.dir.uiName, e.uiName)
+		subdirs = append(subdirs, e)
+	}
+
+	listings, infos := readSubdirs(subdirs, ancestors)
+	for i, l := range listings {
+		fn(l)
+		if infos[i] != nil {
+			walkLevel(l, append(slices.Clip(ancestors), infos[i]), level+1, fn)
+		}
+	}
+}
+
+// statSubdir stats the directory d before it is listed and returns d
+// describing the directory itself rather than a link to it, along with its
+// info. It returns an error if d is one of ancestors, to avoid cycles.
+func statSubdir(d entry, ancestors []os.FileInfo) (entry, os.FileInfo, error) {
+	info, err := os.Stat(d.fullPath)
+	if err != nil {
+		return d, nil, err
+	}
+	if isAncestor(ancestors, info) {
+		return d, nil, fmt.Errorf("%s: not listing already-listed directory", d.uiName)
+	}
+	d.info, d.linkMode, d.linkTarget = info, none, ""
+	d.timestamp = statTime(d.fullPath, info, opt.timeField)
+	return d, info, nil
+}
+
+// readSubdirs reads dirs concurrently and returns their listings in order,
+// along with the resolved file info of every directory that was read.
+// ancestors holds the resolved file info of the directory containing dirs
+// and of every directory above it; a directory that [isAncestor] would
+// start a cycle through symbolic links, so it is reported instead of being
+// read and its info is left nil.
+func readSubdirs(dirs []entry, ancestors []os.FileInfo) ([]listing, []os.FileInfo) {
+	listings := make([]listing, len(dirs))
+	infos := make([]os.FileInfo, len(dirs))
+	var wg sync.WaitGroup
+	for i, d := range dirs {
+		wg.Go(func() {
+			d, info, err := statSubdir(d, ancestors)
+			if err != nil {
+				listings[i] = listing{dir: d, errs: []error{err}}
+				return
+			}
+			infos[i] = info
+			listings[i] = readDirEntries(d)
+		})
+	}
+	wg.Wait()
+	return listings, infos
+}
+
+// subdirName labels the directory name inside parent relative to the
+// user-supplied path.
+func subdirName(parent, name string) string {
+	if strings.HasSuffix(parent, string(os.PathSeparator)) {
+		return parent + name
+	}
+	return parent + string(os.PathSeparator) + name
+}
+
+// isAncestor reports whether info describes the same file as any of
+// ancestors, which on Unix means they share device and inode numbers.
+func isAncestor(ancestors []os.FileInfo, info os.FileInfo) bool {
+	return slices.ContainsFunc(ancestors, func(a os.FileInfo) bool {
+		return os.SameFile(a, info)
+	})
+}
+
+// isDotEntry reports whether e is one of the virtual '.' and '..' entries
+// added to directory listings by -a.
+func isDotEntry(e entry) bool {
+	return e.uiName == "." || e.uiName == ".."
+}
+
 // collectEntries creates entries from paths and separates files from directories.
-func collectEntries(paths []string) (files, dirs []entry) {
+// Paths that cannot be read are reported in errs.
+func collectEntries(paths []string) (files, dirs []entry, errs []error) {
 	for _, p := range paths {
 		abs, err := filepath.Abs(p)
 		if err != nil {
@@ -179,7 +494,7 @@ func collectEntries(paths []string) (files, dirs []entry) {
 
 		ent, err := newEntry(abs, p)
 		if err != nil {
-			showError(err)
+			errs = append(errs, err)
 			continue
 		}
 
@@ -190,121 +505,212 @@ func collectEntries(paths []string) (files, dirs []entry) {
 			files = append(files, ent)
 		}
 	}
-	return files, dirs
+	return files, dirs, errs
 }
 
-// readDirEntries reads d and returns its entries.
-func readDirEntries(d entry) []entry {
-	ents, err := readDir(d.fullPath)
+// A listing holds the entries read from a directory along with any errors
+// encountered while reading it.
+type listing struct {
+	dir  entry
+	ents []entry
+	errs []error
+}
+
+// dotEntries returns the virtual '.' and '..' entries of the directory d.
+// If '..' cannot be read, only '.' is returned, along with the error.
+func dotEntries(d entry) ([]entry, error) {
+	d.uiName = "."
+	d.sortName = "."
+	dotdot, err := newEntry(filepath.Join(d.fullPath, ".."), "..")
 	if err != nil {
-		showError(err)
-		return nil
+		return []entry{d}, err
+	}
+	return []entry{d, dotdot}, nil
+}
+
+// readDirEntries reads d and returns its listing.
+// Errors are collected rather than shown, so that concurrent reads do not
+// interleave them with unrelated output.
+func readDirEntries(d entry) listing {
+	ents, errs := readDir(d.fullPath)
+	l := listing{dir: d, errs: errs}
+	if ents == nil && len(errs) > 0 {
+		return l
 	}
 
 	if opt.all {
-		// Create virtual '.' and '..' entries.
-		d.uiName = "."
-		d.sortName = "."
-		d.dirCount = len(ents) // avoid useless reads later
-		d2, err := newEntry(filepath.Join(d.fullPath, ".."), "..")
+		dots, err := dotEntries(d)
 		if err != nil {
-			showError(err)
-			ents = append(ents, d)
-		} else {
-			ents = append(ents, d, d2)
+			l.errs = append(l.errs, err)
 		}
+		dots[0].dirCount = len(ents) // avoid useless reads later
+		ents = append(ents, dots...)
 	} else {
 		ents = slices.DeleteFunc(ents, isHidden)
 	}
 
-	if opt.long && opt.git {
+	if gitEnabled() {
 		attachGitToDir(d.fullPath, ents)
 	}
+	if opt.du {
+		attachDirSizes(ents)
+	}
 	sortEntries(ents)
-	return ents
+	l.ents = ents
+	return l
+}
+
+// printListing shows l's errors and prints its entries.
+func printListing(l listing) {
+	showErrors(l.errs)
+	printEntries(l.ents)
+	if opt.summary {
+		// Leave out the virtual . and .. entries.
+		printSummary(slices.DeleteFunc(slices.Clone(l.ents), isDotEntry))
+	}
 }
 
 // sortEntries sorts ents according to the active sort and grouping options.
+// The sort keys are applied in order, followed by the name as a final
+// tiebreaker; -r reverses all of them, but not the grouping of -dirsfirst.
 func sortEntries(ents []entry) {
-	// Always sort by name first.
+	if opt.sort.isUnsorted() {
+		return
+	}
+
+	compareNames := strings.Compare
+	if opt.naturalSort {
+		compareNames = naturalCompare
+	}
+
 	slices.SortFunc(ents, func(a, b entry) int {
+		if opt.dirsFirst && a.dirLike != b.dirLike {
+			if a.dirLike {
+				return -1
+			}
+			return 1
+		}
+
+		for _, k := range opt.sort {
+			c := compareBy(k.field, a, b, compareNames)
+			if k.reverse != opt.reverse {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+
 		if opt.reverse {
-			return strings.Compare(b.sortName, a.sortName)
+			return compareNames(b.sortName, a.sortName)
 		}
-		return strings.Compare(a.sortName, b.sortName)
+		return compareNames(a.sortName, b.sortName)
 	})
+}
 
-	switch opt.sort {
+// compareBy compares a and b by field, using compareNames for names.
+func compareBy(field sortField, a, b entry, compareNames func(a, b string) int) int {
+	switch field {
+	case name:
+		return compareNames(a.sortName, b.sortName)
+	case natural:
+		return naturalCompare(a.sortName, b.sortName)
 	case extension:
-		slices.SortStableFunc(ents, func(a, b entry) int {
-			if opt.reverse {
-				return strings.Compare(filepath.Ext(b.sortName), filepath.Ext(a.sortName))
-			}
-			return strings.Compare(filepath.Ext(a.sortName), filepath.Ext(b.sortName))
-		})
+		return compareNames(filepath.Ext(a.sortName), filepath.Ext(b.sortName))
 	case size:
-		slices.SortStableFunc(ents, func(a, b entry) int {
-			if opt.reverse {
-				return cmp.Compare(b.info.Size(), a.info.Size())
-			}
-			return cmp.Compare(a.info.Size(), b.info.Size())
-		})
+		return cmp.Compare(fileSize(a), fileSize(b))
 	case mtime:
-		slices.SortStableFunc(ents, func(a, b entry) int {
-			if opt.reverse {
-				return b.info.ModTime().Compare(a.info.ModTime())
-			}
-			return a.info.ModTime().Compare(b.info.ModTime())
-		})
+		return a.timestamp.Compare(b.timestamp)
 	case git:
-		slices.SortStableFunc(ents, func(a, b entry) int {
-			if opt.reverse {
-				return strings.Compare(b.gitStatus, a.gitStatus)
-			}
-			return strings.Compare(a.gitStatus, b.gitStatus)
-		})
+		return strings.Compare(a.gitStatus, b.gitStatus)
+	default:
+		return 0
 	}
+}
 
-	if opt.dirsFirst {
-		slices.SortStableFunc(ents, func(a, b entry) int {
-			switch {
-			case a.dirLike == b.dirLike:
-				return 0
-			case a.dirLike:
-				return -1
-			default:
-				return 1
+// naturalCompare compares a and b like [strings.Compare], except that runs
+// of ASCII digits are compared by their numeric value, so "image_2.png"
+// sorts before "image_10.png". Digit runs of any length are supported.
+// If a and b differ only in the leading zeros of their numbers, the first
+// such number with fewer zeros sorts first.
+func naturalCompare(a, b string) int {
+	zeros := 0 // result of the first leading zero comparison
+	for a != "" && b != "" {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			// Bytewise comparison of UTF-8 preserves code point order,
+			// and ASCII digits never occur inside multi-byte sequences.
+			if a[0] != b[0] {
+				return cmp.Compare(a[0], b[0])
 			}
-		})
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		i := digitRun(a)
+		j := digitRun(b)
+		na := strings.TrimLeft(a[:i], "0")
+		nb := strings.TrimLeft(b[:j], "0")
+		// Without leading zeros, the longer number is the greater one.
+		if c := cmp.Compare(len(na), len(nb)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(na, nb); c != 0 {
+			return c
+		}
+		if zeros == 0 {
+			zeros = cmp.Compare(i, j)
+		}
+		a, b = a[i:], b[j:]
+	}
+
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return zeros
+}
+
+// digitRun returns the length of the run of ASCII digits at the start of s.
+func digitRun(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
 	}
+	return i
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
 }
 
 // readDir is like [os.ReadDir], but returns a slice of [entry] rather than
 // [os.DirEntry] and does not sort by filename.
-func readDir(path string) ([]entry, error) {
+// Entries that cannot be read are skipped and reported in errs; if the
+// directory itself cannot be read, ents is nil.
+func readDir(path string) (ents []entry, errs []error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 	defer f.Close()
 
 	names, err := f.Readdirnames(-1)
 	if err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 
-	ents := make([]entry, 0, len(names))
+	ents = make([]entry, 0, len(names))
 	for _, name := range names {
 		full := filepath.Join(path, name)
 		ent, err := newEntry(full, name)
 		if err != nil {
-			showError(err)
+			errs = append(errs, err)
 			continue
 		}
 		ents = append(ents, ent)
 	}
 
-	return ents, nil
+	return ents, errs
 }
 
 // countDirEntries returns the number of entries in dir.
@@ -344,63 +750,251 @@ func printEntries(ents []entry) {
 	}
 }
 
-// A row holds the formatted columns of an [entry] in long output.
-type row struct {
-	modeStr string
-	sizeStr string
-	timeStr string
-	gitStr  string
-	nameStr string
+// A column describes a column of long output.
+type column struct {
+	label string             // header row label
+	right bool               // whether cells are right-aligned
+	cell  func(entry) string // formats the cell of an entry
+}
+
+// longColumn returns the long output column called name.
+func longColumn(name string) (column, bool) {
+	switch name {
+	case "mode":
+		return column{label: "Mode", cell: modeCell}, true
+	case "flags":
+		return column{label: "Flags", cell: inodeFlags}, true
+	case "inode":
+		return column{label: "Inode", right: true, cell: inodeCell}, true
+	case "links":
+		return column{label: "Links", right: true, cell: linksCell}, true
+	case "owner":
+		return column{label: "Owner", cell: ownerCell}, true
+	case "group":
+		return column{label: "Group", cell: groupCell}, true
+	case "context":
+		return column{label: "Context", cell: securityContext}, true
+	case "size":
+		return column{label: "Size", right: true, cell: sizeCell}, true
+	case "time":
+		// Right-align relative times so their units line up.
+		right := opt.timeFmtOld == relativeFmt || opt.timeFmtNew == relativeFmt
+		return column{label: opt.timeField.label(), right: right, cell: timeCell}, true
+	case "git":
+		return column{label: "Git", cell: func(e entry) string { return colorGit(e.gitStatus) }}, true
+	case "name":
+		return column{label: "Name", cell: formatName}, true
+	default:
+		return column{}, false
+	}
+}
+
+// defaultColumns is the column layout used unless -columns is given.
+// Owner and group are left out on systems that do not provide them.
+var defaultColumns = columnList{"mode", "owner", "group", "size", "time", "git", "name"}
+
+// columnList is the list of columns shown in long output, in order.
+type columnList []string
+
+// longColumnNames returns the names of the columns printed by [printLong].
+// The name column is always included so that no row can come out empty.
+func longColumnNames() columnList {
+	names := opt.columns
+	if len(names) == 0 {
+		names = defaultColumns
+	}
+	if !slices.Contains(names, "name") {
+		names = append(slices.Clone(names), "name")
+	}
+	if opt.flags && !slices.Contains(names, "flags") {
+		// Place the flags after the mode (or else first).
+		i := slices.Index(names, "mode") + 1
+		names = slices.Insert(slices.Clone(names), i, "flags")
+	}
+	if opt.context && !slices.Contains(names, "context") {
+		// Like GNU ls, place the context before the size (or else the name).
+		i := slices.Index(names, "size")
+		if i < 0 {
+			i = slices.Index(names, "name")
+		}
+		if i < 0 {
+			i = len(names)
+		}
+		names = slices.Insert(slices.Clone(names), i, "context")
+	}
+	return names
+}
+
+// Set implements the [flag.Value] interface.
+func (c *columnList) Set(val string) error {
+	var names columnList
+	for name := range strings.SplitSeq(val, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := longColumn(name); !ok {
+			return errors.New("must be a comma-separated list of inode, mode, flags, links, owner, group, context, size, time, git, or name")
+		}
+		names = append(names, name)
+	}
+	*c = names
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (c columnList) String() string {
+	return strings.Join(c, ",")
 }
 
 // printLong prints ents with metadata columns and aligns them by content width.
+// Columns without any content, such as Git status outside of repositories,
+// are left out.
 func printLong(ents []entry) {
-	rows := make([]row, 0, len(ents))
-	var sizeWidth, timeWidth int
+	names := longColumnNames()
+	cols := make([]column, len(names))
+	for i, name := range names {
+		cols[i], _ = longColumn(name)
+	}
 
 	// Format once; print aligned after widths are known.
-	for _, e := range ents {
-		var sizeStr string
-		if !e.dirLike {
-			sizeStr = humanReadable(e.info.Size())
-		} else if e.dirCount >= 0 {
-			sizeStr = strconv.Itoa(e.dirCount)
-		} else if n, err := countDirEntries(e.fullPath); err != nil {
-			sizeStr = "!"
-		} else {
-			sizeStr = strconv.Itoa(n)
+	rows := make([][]string, len(ents))
+	widths := make([]int, len(cols))
+	for i, e := range ents {
+		rows[i] = make([]string, len(cols))
+		for j, c := range cols {
+			s := c.cell(e)
+			rows[i][j] = s
+			widths[j] = max(widths[j], visibleLen(s))
 		}
-		if n := len(sizeStr); n > sizeWidth {
-			sizeWidth = n
+	}
+
+	var visible []int
+	for j := range cols {
+		if widths[j] > 0 {
+			visible = append(visible, j)
 		}
+	}
 
-		timeStr := formatTime(e.info.ModTime())
-		if n := len(timeStr); n > timeWidth {
-			timeWidth = n
+	if opt.header {
+		labels := make([]string, len(cols))
+		for _, j := range visible {
+			widths[j] = max(widths[j], len(cols[j].label))
+			labels[j] = sgr(metaStyle("hd"), cols[j].label)
 		}
+		printRow(labels, cols, widths, visible)
+	}
+	for i, r := range rows {
+		printRow(r, cols, widths, visible)
+		if opt.xattr {
+			printXattrs(ents[i])
+		}
+	}
+}
 
-		rows = append(rows, row{
-			modeStr: mode(e),
-			sizeStr: sizeStr,
-			timeStr: timeStr,
-			gitStr:  e.gitStatus,
-			nameStr: formatName(e),
-		})
+// printRow prints the visible cells of a row of long output, padded to
+// widths and separated by spaces. The last cell is not padded unless it is
+// right-aligned.
+func printRow(cells []string, cols []column, widths, visible []int) {
+	var b strings.Builder
+	for k, j := range visible {
+		if k > 0 {
+			b.WriteByte(' ')
+		}
+		pad := strings.Repeat(" ", widths[j]-visibleLen(cells[j]))
+		switch {
+		case cols[j].right:
+			b.WriteString(pad + cells[j])
+		case k == len(visible)-1:
+			b.WriteString(cells[j])
+		default:
+			b.WriteString(cells[j] + pad)
+		}
+	}
+	fmt.Println(b.String())
+}
+
+// inodeCell formats the inode column of e.
+func inodeCell(e entry) string {
+	ino, ok := fileInode(e.info)
+	if !ok {
+		return ""
 	}
+	return strconv.FormatUint(ino, 10)
+}
 
-	gitWidth := len(ents[0].gitStatus) // Always 0 or 2 for every entry.
-	if gitWidth > 0 {
-		gitWidth++ // needs separation if visible
+// linksCell formats the hard link count column of e.
+func linksCell(e entry) string {
+	n, ok := fileLinks(e.info)
+	if !ok {
+		return ""
 	}
-	for _, r := range rows {
-		fmt.Printf("%s %*s %-*s%*s %s\n",
-			r.modeStr,
-			sizeWidth, r.sizeStr,
-			timeWidth, r.timeStr,
-			gitWidth, r.gitStr,
-			r.nameStr,
-		)
+	return strconv.FormatUint(n, 10)
+}
+
+// fileSize returns the size of e: the total size of its tree if measured
+// by -du, or else its own size as returned by [sizeOf].
+func fileSize(e entry) int64 {
+	if e.duSize >= 0 {
+		return e.duSize
+	}
+	return sizeOf(e.info)
+}
+
+// sizeOf returns the size of the file described by info according to
+// -size-style: the number of bytes allocated on disk for blocks, or else
+// its apparent size.
+func sizeOf(info os.FileInfo) int64 {
+	if opt.sizeStyle == blockSize {
+		if n, ok := allocatedSize(info); ok {
+			return n
+		}
 	}
+	return info.Size()
+}
+
+// sizeCell formats the size column of e: its size in the style chosen by
+// -size-style for files (and directories with -du) and the number of items
+// inside for directories.
+func sizeCell(e entry) string {
+	if !e.dirLike || opt.du {
+		n := fileSize(e)
+		return colorSize(n, formatSize(n))
+	}
+	n := e.dirCount
+	if n < 0 {
+		var err error
+		if n, err = countDirEntries(e.fullPath); err != nil {
+			return "!"
+		}
+	}
+	return sgr(metaStyle("sn"), strconv.Itoa(n))
+}
+
+// timeCell formats the time column of e.
+func timeCell(e entry) string {
+	if e.timestamp.IsZero() {
+		return "-" // placeholder for unavailable timestamps
+	}
+	return colorTime(e.timestamp, formatTime(e.timestamp))
+}
+
+// visibleLen returns the number of characters of s that take up space on
+// the terminal, ignoring ANSI escape sequences.
+func visibleLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], csi) {
+			// Skip parameters up to and including the final byte.
+			i += len(csi)
+			for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+				i++
+			}
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+	return n
 }
 
 // print1PerLine prints each entry in ents on its own line.
@@ -417,7 +1011,7 @@ func printShort(ents []entry) {
 	nameWidth := 0
 
 	for _, e := range ents {
-		if n := len(e.uiName); n > nameWidth {
+		if n := nameLen(e); n > nameWidth {
 			nameWidth = n
 		}
 	}
@@ -449,7 +1043,7 @@ func printShort(ents []entry) {
 				continue
 			}
 
-			n := len(ents[i].uiName)
+			n := nameLen(e)
 			if suffix := indicator(e); suffix != 0 {
 				n += 1
 			}
@@ -460,18 +1054,36 @@ func printShort(ents []entry) {
 	}
 }
 
+// nameLen returns the printed length of e's name, without its indicator.
+func nameLen(e entry) int {
+	n := len(e.uiName)
+	if opt.context {
+		n += len(securityContext(e)) + 1
+	}
+	return n
+}
+
 // formatName adds colours and a type indicator to e's uiName and returns it.
+// In long mode, it also shows symlink targets and file capabilities.
+// Otherwise, it is prefixed with the security context if -Z is set.
 func formatName(e entry) string {
-	name := colorize(e)
+	name := e.indent + colorize(e)
+	if opt.context && !opt.long {
+		name = securityContext(e) + " " + name
+	}
 	suffix := indicator(e)
 	switch {
-	case suffix == 0:
-		return name
 	case suffix == '@' && opt.long:
-		return name + "@ -> " + e.linkTarget
-	default:
-		return name + string(suffix)
+		return name + "@ " + sgr(metaStyle("ar"), "->") + " " + e.linkTarget
+	case suffix != 0:
+		name += string(suffix)
 	}
+	if opt.long && e.info.Mode().IsRegular() {
+		if caps := fileCapabilities(e.fullPath); caps != "" {
+			name += " [" + caps + "]"
+		}
+	}
+	return name
 }
 
 // indicator returns an ls-style type indicator for e, or 0 if none applies.
@@ -493,14 +1105,47 @@ func indicator(e entry) rune {
 	}
 }
 
-// humanReadable formats size using binary units.
-func humanReadable(size int64) string {
-	if size < 1024 {
-		return fmt.Sprintf("%dB", size)
+// formatSize formats size according to -size-style.
+func formatSize(size int64) string {
+	switch opt.sizeStyle {
+	case siSize:
+		return humanReadable(size, true)
+	case byteSize:
+		if opt.thousands {
+			return groupThousands(size)
+		}
+		return strconv.FormatInt(size, 10)
+	default:
+		return humanReadable(size, false)
 	}
+}
 
+// groupThousands formats n with commas between groups of three digits.
+func groupThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 && s[i-1] != '-' {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// humanReadable formats size using binary units, or decimal SI units if si
+// is set.
+func humanReadable(size int64, si bool) string {
 	base := 1024.0
 	units := []string{"K", "M", "G", "T", "P"}
+	if si {
+		base = 1000
+		units[0] = "k"
+	}
+	if float64(size) < base {
+		return fmt.Sprintf("%dB", size)
+	}
+
 	v := float64(size)
 
 	for _, u := range units {
@@ -518,10 +1163,51 @@ func humanReadable(size int64) string {
 
 // formatTime formats t according to the active time format options.
 func formatTime(t time.Time) string {
-	if t.Year() == currYear {
-		return t.Format(opt.timeFmtNew)
+	t = t.In(opt.location)
+	// Like GNU ls, treat files from the future as not recent.
+	layout := opt.timeFmtOld
+	if age := now().Sub(t); age >= 0 && age < opt.recent {
+		layout = opt.timeFmtNew
+	}
+	switch {
+	case layout == relativeFmt:
+		return relativeTime(t)
+	case strings.Contains(layout, "%"):
+		return strftime(t, layout)
+	default:
+		return t.Format(layout)
 	}
-	return t.Format(opt.timeFmtOld)
+}
+
+// relativeFmt is the time format that selects [relativeTime].
+const relativeFmt = "relative"
+
+// relativeTime formats t as the time elapsed since now in its largest unit
+// (e.g. "5m", "3h", "2d", "4mo"), or "in" followed by the time remaining
+// for future times.
+func relativeTime(t time.Time) string {
+	d := now().Sub(t)
+	prefix := ""
+	if d < 0 {
+		d, prefix = -d, "in "
+	}
+
+	var s string
+	switch {
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < day:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d < month:
+		s = fmt.Sprintf("%dd", d/day)
+	case d < year:
+		s = fmt.Sprintf("%dmo", d/month)
+	default:
+		s = fmt.Sprintf("%dy", d/year)
+	}
+	return prefix + s
 }
 
 // tildePath abbreviates an absolute path under the home directory using "~".
@@ -539,7 +1225,23 @@ func tildePath(path string) string {
 	}
 }
 
+// printDirHeader prints the header above the listing of d.
+func printDirHeader(d entry) {
+	fmt.Println(sgr(metaStyle("dp"), tildePath(d.uiName)+":"))
+}
+
 // showError prints e to stderr, prefixed by the program name.
 func showError(e error) {
-	fmt.Fprintf(os.Stderr, "%s: %v\n", progName, e)
+	msg := fmt.Sprintf("%s: %v", progName, e)
+	if colors.stderr {
+		msg = sgr(colors.meta["er"], msg)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+// showErrors prints each error in errs using [showError].
+func showErrors(errs []error) {
+	for _, err := range errs {
+		showError(err)
+	}
 }
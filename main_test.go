@@ -0,0 +1,190 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"a", "a", 0},
+		{"", "0", -1},
+		{"a", "a0", -1},
+		{"a", "b", -1},
+		{"9", "10", -1},
+		{"image_2.png", "image_10.png", -1},
+		{"a1", "aa", -1},
+
+		// Leading zeros only break ties, decided by the first number
+		// that differs in them.
+		{"a1", "a01", -1},
+		{"a01", "a001", -1},
+		{"a0", "a00", -1},
+		{"a01", "a2", -1},
+		{"a1b02", "a01b2", -1},
+		{"a1b3", "a01b2", 1},
+
+		// Digit runs longer than any integer type.
+		{"x123456789012345678901234567890", "x123456789012345678901234567891", -1},
+		{"x99999999999999999999999", "x100000000000000000000000", -1},
+		{"x000000000000000000000000000001", "x2", -1},
+		{"x18446744073709551616", "x18446744073709551615", 1},
+
+		// Multi-byte UTF-8 next to digits.
+		{"é2", "é10", -1},
+		{"日本1", "日本01", -1},
+		{"2é", "10é", -1},
+		{"a10é", "a10ö", -1},
+		{"ä1", "a2", 1},
+		{"файл9", "файл10", -1},
+	}
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := naturalCompare(tt.b, tt.a); got != -tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestSortEntriesNatural(t *testing.T) {
+	tests := []struct {
+		sort        string
+		naturalSort bool
+		reverse     bool
+		dirsFirst   bool
+		want        []string
+	}{
+		{"name", false, false, false, []string{"img1", "img10", "img2", "zdir20", "zdir3"}},
+		{"name", true, false, false, []string{"img1", "img2", "img10", "zdir3", "zdir20"}},
+		{"natural", false, false, false, []string{"img1", "img2", "img10", "zdir3", "zdir20"}},
+		{"natural", false, true, false, []string{"zdir20", "zdir3", "img10", "img2", "img1"}},
+		{"natural", false, false, true, []string{"zdir3", "zdir20", "img1", "img2", "img10"}},
+		{"natural", false, true, true, []string{"zdir20", "zdir3", "img10", "img2", "img1"}},
+		{"-natural", false, false, true, []string{"zdir20", "zdir3", "img10", "img2", "img1"}},
+		{"-natural", false, true, true, []string{"zdir3", "zdir20", "img1", "img2", "img10"}},
+	}
+
+	saved := opt
+	t.Cleanup(func() { opt = saved })
+
+	for _, tt := range tests {
+		opt = options{naturalSort: tt.naturalSort, reverse: tt.reverse, dirsFirst: tt.dirsFirst}
+		if err := opt.sort.Set(tt.sort); err != nil {
+			t.Fatal(err)
+		}
+
+		var ents []entry
+		for _, name := range []string{"img10", "zdir3", "img2", "Img1", "zdir20"} {
+			ents = append(ents, entry{
+				uiName:   name,
+				sortName: strings.ToLower(name),
+				dirLike:  strings.HasPrefix(name, "zdir"),
+			})
+		}
+		sortEntries(ents)
+
+		var got []string
+		for _, e := range ents {
+			got = append(got, e.sortName)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s, natural %v, reverse %v, dirsfirst %v: got %v, want %v",
+				tt.sort, tt.naturalSort, tt.reverse, tt.dirsFirst, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	ref := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	saved := now
+	t.Cleanup(func() { now = saved })
+	now = func() time.Time { return ref }
+
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{59 * time.Second, "59s"},
+		{59*time.Second + 999*time.Millisecond, "59s"},
+		{time.Minute, "1m"},
+		{59*time.Minute + 59*time.Second, "59m"},
+		{time.Hour, "1h"},
+		{23*time.Hour + 59*time.Minute, "23h"},
+		{day, "1d"},
+		{month - time.Second, "30d"},
+		{month, "1mo"},
+		{year - time.Second, "11mo"},
+		{year, "1y"},
+		{2*year + month, "2y"},
+
+		{-time.Second, "in 1s"},
+		{-time.Minute, "in 1m"},
+		{-(day - time.Second), "in 23h"},
+		{-day, "in 1d"},
+		{-month, "in 1mo"},
+		{-year, "in 1y"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(ref.Add(-tt.age)); got != tt.want {
+			t.Errorf("relativeTime(now - %v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ref := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	savedNow, savedOpt := now, opt
+	t.Cleanup(func() { now, opt = savedNow, savedOpt })
+	now = func() time.Time { return ref }
+
+	tests := []struct {
+		fmtOld, fmtNew string
+		age            time.Duration
+		want           string
+	}{
+		{"old 2006-01-02", "new 15:04", 0, "new 12:00"},
+		{"old 2006-01-02", "new 15:04", time.Hour, "new 11:00"},
+		{"old 2006-01-02", "new 15:04", 24*time.Hour - time.Second, "new 12:00"},
+		{"old 2006-01-02", "new 15:04", 24 * time.Hour, "old 2024-06-14"},
+		{"old 2006-01-02", "new 15:04", 30 * day, "old 2024-05-16"},
+		// Files from the future are not recent.
+		{"old 2006-01-02", "new 15:04", -time.Minute, "old 2024-06-15"},
+		{"%Y-%m-%d", "%H:%M", time.Hour, "11:00"},
+		{"%Y-%m-%d", "%H:%M", 2 * 24 * time.Hour, "2024-06-13"},
+		{relativeFmt, "15:04", time.Hour, "11:00"},
+		{relativeFmt, "15:04", 2 * 24 * time.Hour, "2d"},
+		{relativeFmt, "15:04", -time.Hour, "in 1h"},
+	}
+	for _, tt := range tests {
+		opt = options{
+			timeFmtOld: tt.fmtOld,
+			timeFmtNew: tt.fmtNew,
+			location:   time.UTC,
+			recent:     24 * time.Hour,
+		}
+		if got := formatTime(ref.Add(-tt.age)); got != tt.want {
+			t.Errorf("formatTime(now - %v) with %q, %q = %q, want %q",
+				tt.age, tt.fmtOld, tt.fmtNew, got, tt.want)
+		}
+	}
+
+	// The location applies before formatting.
+	opt = options{
+		timeFmtOld: "15:04 MST",
+		timeFmtNew: "15:04 MST",
+		location:   time.FixedZone("XST", 3*60*60),
+		recent:     time.Hour,
+	}
+	if got, want := formatTime(ref), "15:00 XST"; got != want {
+		t.Errorf("formatTime in XST = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "os/user"
+
+var (
+	userNames  = map[string]string{} // user ID to name
+	groupNames = map[string]string{} // group ID to name
+)
+
+// ownerCell formats the owner column of e.
+func ownerCell(e entry) string {
+	uid, _, ok := fileOwner(e.info)
+	if !ok || opt.numeric {
+		return uid
+	}
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+
+	// Fall back to the numeric ID if it has no name.
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
+
+// groupCell formats the group column of e.
+func groupCell(e entry) string {
+	_, gid, ok := fileOwner(e.info)
+	if !ok || opt.numeric {
+		return gid
+	}
+	if name, ok := groupNames[gid]; ok {
+		return name
+	}
+
+	// Fall back to the numeric ID if it has no name.
+	name := gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		name = g.Name
+	}
+	groupNames[gid] = name
+	return name
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// fileOwner returns the numeric user and group IDs of the file described
+// by info. ok is false if they are unavailable, as is always the case on
+// these systems.
+func fileOwner(info os.FileInfo) (uid, gid string, ok bool) {
+	return "", "", false
+}
+
+// allocatedSize returns the number of bytes allocated on disk for the file
+// described by info.
+func allocatedSize(info os.FileInfo) (n int64, ok bool) {
+	return 0, false
+}
+
+// fileLinks returns the number of hard links to the file described by info.
+func fileLinks(info os.FileInfo) (n uint64, ok bool) {
+	return 0, false
+}
+
+// fileDevice returns the ID of the device containing the file described
+// by info.
+func fileDevice(info os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
+
+// fileInode returns the inode number of the file described by info.
+func fileInode(info os.FileInfo) (ino uint64, ok bool) {
+	return 0, false
+}
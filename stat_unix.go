@@ -0,0 +1,57 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the numeric user and group IDs of the file described
+// by info. ok is false if they are unavailable.
+func fileOwner(info os.FileInfo) (uid, gid string, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return "", "", false
+	}
+	return strconv.FormatUint(uint64(st.Uid), 10), strconv.FormatUint(uint64(st.Gid), 10), true
+}
+
+// allocatedSize returns the number of bytes allocated on disk for the file
+// described by info.
+func allocatedSize(info os.FileInfo) (n int64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return 0, false
+	}
+	return int64(st.Blocks) * 512, true
+}
+
+// fileLinks returns the number of hard links to the file described by info.
+func fileLinks(info os.FileInfo) (n uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return 0, false
+	}
+	return uint64(st.Nlink), true
+}
+
+// fileDevice returns the ID of the device containing the file described
+// by info.
+func fileDevice(info os.FileInfo) (dev uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// fileInode returns the inode number of the file described by info.
+func fileInode(info os.FileInfo) (ino uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return 0, false
+	}
+	return uint64(st.Ino), true
+}
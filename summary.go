@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A summary counts entries by type and adds up their sizes.
+type summary struct {
+	files, dirs, links, others int
+	size                       int64 // size of files and of directories measured by -du
+}
+
+// grandTotal accumulates the summaries of every listing for -summary.
+var grandTotal summary
+
+// add counts ents in s.
+func (s *summary) add(ents []entry) {
+	for _, e := range ents {
+		m := e.info.Mode()
+		switch {
+		case m&os.ModeSymlink != 0:
+			s.links++
+		case m.IsDir():
+			s.dirs++
+		case m.IsRegular():
+			s.files++
+		default:
+			s.others++
+		}
+		if !m.IsDir() || e.duSize >= 0 {
+			s.size += fileSize(e)
+		}
+	}
+}
+
+// String returns s as "SIZE: N files, N directories[, N symlinks][, N other]".
+func (s summary) String() string {
+	parts := []string{
+		countOf(s.files, "file", "files"),
+		countOf(s.dirs, "directory", "directories"),
+	}
+	if s.links > 0 {
+		parts = append(parts, countOf(s.links, "symlink", "symlinks"))
+	}
+	if s.others > 0 {
+		parts = append(parts, countOf(s.others, "other", "other"))
+	}
+	return formatSize(s.size) + ": " + strings.Join(parts, ", ")
+}
+
+// countOf formats n followed by the singular or plural noun.
+func countOf(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return strconv.Itoa(n) + " " + plural
+}
+
+// printSummary prints a total line for ents and adds them to grandTotal.
+func printSummary(ents []entry) {
+	var s summary
+	s.add(ents)
+	grandTotal.add(ents)
+	fmt.Println("total", s)
+}
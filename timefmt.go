@@ -0,0 +1,254 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Default time formats for non-recent and recent files.
+const (
+	defaultTimeFmtOld = "Jan _2  2006"
+	defaultTimeFmtNew = "Jan _2 15:04"
+)
+
+// Units accepted by [parseDuration] in addition to those of
+// [time.ParseDuration]. Like GNU ls, a year is a mean Gregorian year.
+const (
+	day   = 24 * time.Hour
+	year  = 31556952 * time.Second
+	month = year / 12
+)
+
+// durationUnits maps the unit suffixes accepted by [parseDuration] to
+// their lengths.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  day,
+	"w":  7 * day,
+	"mo": month,
+	"y":  year,
+}
+
+// parseDuration parses a non-negative duration such as "6mo", "1.5y" or
+// "1d12h". Like [time.ParseDuration], it takes a sequence of decimal
+// numbers with unit suffixes, but it also accepts days, weeks, months and
+// years (d, w, mo, y).
+func parseDuration(s string) (time.Duration, error) {
+	errInvalid := errors.New("must be a non-negative duration such as 6mo, 30d or 1d12h")
+	if s == "0" {
+		return 0, nil
+	}
+	var total float64
+	for rest := strings.TrimPrefix(s, "+"); rest != ""; {
+		numLen := strings.IndexFunc(rest, unicode.IsLetter)
+		if numLen <= 0 || strings.Trim(rest[:numLen], "0123456789.") != "" {
+			return 0, errInvalid
+		}
+		unitLen := strings.IndexFunc(rest[numLen:], func(r rune) bool { return !unicode.IsLetter(r) })
+		if unitLen < 0 {
+			unitLen = len(rest) - numLen
+		}
+		n, err := strconv.ParseFloat(rest[:numLen], 64)
+		unit, ok := durationUnits[rest[numLen:numLen+unitLen]]
+		if err != nil || !ok {
+			return 0, errInvalid
+		}
+		total += n * float64(unit)
+		rest = rest[numLen+unitLen:]
+	}
+	if s == "" || total > math.MaxInt64 {
+		return 0, errInvalid
+	}
+	return time.Duration(total), nil
+}
+
+// A timeStyle is a named pair of time formats for non-recent and recent
+// files, as selected by -time-style.
+type timeStyle struct {
+	name           string
+	fmtOld, fmtNew string
+}
+
+// Set implements the [flag.Value] interface.
+// Like GNU ls, it accepts the names of predefined styles and custom
+// formats of the form +FORMAT or +OLD\nNEW.
+func (s *timeStyle) Set(val string) error {
+	var fmtOld, fmtNew string
+	switch val {
+	case "full-iso":
+		fmtOld = "2006-01-02 15:04:05.000000000 -0700"
+		fmtNew = fmtOld
+	case "long-iso":
+		fmtOld = "2006-01-02 15:04"
+		fmtNew = fmtOld
+	case "iso":
+		fmtOld, fmtNew = "2006-01-02 ", "01-02 15:04"
+	case "locale", "default":
+		fmtOld, fmtNew = defaultTimeFmtOld, defaultTimeFmtNew
+	case "relative":
+		fmtOld, fmtNew = relativeFmt, relativeFmt
+	default:
+		f, ok := strings.CutPrefix(val, "+")
+		if !ok || f == "" {
+			return errors.New("must be full-iso, long-iso, iso, locale, relative, or +FORMAT")
+		}
+		fmtOld, fmtNew, _ = strings.Cut(f, "\n")
+		fmtNew = cmp.Or(fmtNew, fmtOld)
+	}
+	*s = timeStyle{val, fmtOld, fmtNew}
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (s timeStyle) String() string {
+	return s.name
+}
+
+// strftime formats t according to format, which uses the conversion
+// specifications of strftime(3) and GNU date(1), such as "%Y-%m-%d %H:%M".
+// The flags '-' (no padding), '_' (pad with spaces) and '0' (pad with
+// zeros) may follow '%'. Unknown conversions are copied unchanged.
+func strftime(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		start := i
+		i++
+
+		var pad byte
+		if c := format[i]; (c == '-' || c == '_' || c == '0') && i+1 < len(format) {
+			pad = c
+			i++
+		}
+		colon := format[i] == ':' && i+1 < len(format) && format[i+1] == 'z'
+		if colon {
+			i++
+		}
+
+		// num writes n padded to width with fill, unless overridden by pad.
+		num := func(n, width int, fill byte) {
+			switch pad {
+			case '-':
+				width = 0
+			case '_':
+				fill = ' '
+			case '0':
+				fill = '0'
+			}
+			s := strconv.Itoa(n)
+			for range width - len(s) {
+				b.WriteByte(fill)
+			}
+			b.WriteString(s)
+		}
+
+		hour12 := t.Hour() % 12
+		if hour12 == 0 {
+			hour12 = 12
+		}
+		isoYear, isoWeek := t.ISOWeek()
+		yday, wday := t.YearDay()-1, int(t.Weekday())
+
+		switch format[i] {
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Weekday().String())
+		case 'b', 'h':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Month().String())
+		case 'c':
+			b.WriteString(strftime(t, "%a %b %e %H:%M:%S %Y"))
+		case 'C':
+			num(t.Year()/100, 2, '0')
+		case 'd':
+			num(t.Day(), 2, '0')
+		case 'D', 'x':
+			b.WriteString(strftime(t, "%m/%d/%y"))
+		case 'e':
+			num(t.Day(), 2, ' ')
+		case 'F':
+			b.WriteString(strftime(t, "%Y-%m-%d"))
+		case 'g':
+			num(isoYear%100, 2, '0')
+		case 'G':
+			num(isoYear, 4, '0')
+		case 'H':
+			num(t.Hour(), 2, '0')
+		case 'I':
+			num(hour12, 2, '0')
+		case 'j':
+			num(yday+1, 3, '0')
+		case 'k':
+			num(t.Hour(), 2, ' ')
+		case 'l':
+			num(hour12, 2, ' ')
+		case 'm':
+			num(int(t.Month()), 2, '0')
+		case 'M':
+			num(t.Minute(), 2, '0')
+		case 'n':
+			b.WriteByte('\n')
+		case 'N':
+			num(t.Nanosecond(), 9, '0')
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'P':
+			b.WriteString(t.Format("pm"))
+		case 'r':
+			b.WriteString(strftime(t, "%I:%M:%S %p"))
+		case 'R':
+			b.WriteString(strftime(t, "%H:%M"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'S':
+			num(t.Second(), 2, '0')
+		case 't':
+			b.WriteByte('\t')
+		case 'T', 'X':
+			b.WriteString(strftime(t, "%H:%M:%S"))
+		case 'u':
+			num((wday+6)%7+1, 1, '0')
+		case 'U':
+			num((yday+7-wday)/7, 2, '0')
+		case 'V':
+			num(isoWeek, 2, '0')
+		case 'w':
+			num(wday, 1, '0')
+		case 'W':
+			num((yday+7-(wday+6)%7)/7, 2, '0')
+		case 'y':
+			num(t.Year()%100, 2, '0')
+		case 'Y':
+			num(t.Year(), 4, '0')
+		case 'z':
+			if colon {
+				b.WriteString(t.Format("-07:00"))
+			} else {
+				b.WriteString(t.Format("-0700"))
+			}
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteString(format[start : i+1])
+		}
+	}
+	return b.String()
+}
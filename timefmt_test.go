@@ -0,0 +1,164 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	ref := time.Date(2024, 3, 5, 14, 7, 9, 12345678, ist)
+
+	tests := []struct {
+		format, want string
+	}{
+		{"%a", "Tue"},
+		{"%A", "Tuesday"},
+		{"%b", "Mar"},
+		{"%h", "Mar"},
+		{"%B", "March"},
+		{"%c", "Tue Mar  5 14:07:09 2024"},
+		{"%C", "20"},
+		{"%d", "05"},
+		{"%D", "03/05/24"},
+		{"%x", "03/05/24"},
+		{"%e", " 5"},
+		{"%F", "2024-03-05"},
+		{"%g", "24"},
+		{"%G", "2024"},
+		{"%H", "14"},
+		{"%I", "02"},
+		{"%j", "065"},
+		{"%k", "14"},
+		{"%l", " 2"},
+		{"%m", "03"},
+		{"%M", "07"},
+		{"%n", "\n"},
+		{"%N", "012345678"},
+		{"%p", "PM"},
+		{"%P", "pm"},
+		{"%r", "02:07:09 PM"},
+		{"%R", "14:07"},
+		{"%s", "1709627829"},
+		{"%S", "09"},
+		{"%t", "\t"},
+		{"%T", "14:07:09"},
+		{"%X", "14:07:09"},
+		{"%u", "2"},
+		{"%U", "09"},
+		{"%V", "10"},
+		{"%w", "2"},
+		{"%W", "10"},
+		{"%y", "24"},
+		{"%Y", "2024"},
+		{"%z", "+0530"},
+		{"%:z", "+05:30"},
+		{"%Z", "IST"},
+		{"%%", "%"},
+		{"%Y-%m-%d %H:%M", "2024-03-05 14:07"},
+
+		// Padding flags.
+		{"%-d", "5"},
+		{"%_d", " 5"},
+		{"%0e", "05"},
+		{"%-e", "5"},
+		{"%-j", "65"},
+		{"%_j", " 65"},
+		{"%-H", "14"},
+		{"%_m", " 3"},
+		{"%-I", "2"},
+		{"%0l", "02"},
+		{"%-m/%-d", "3/5"},
+
+		// Unknown conversions and incomplete specifications at the end.
+		{"%q", "%q"},
+		{"%-q", "%-q"},
+		{"%:y", "%:y"},
+		{"%", "%"},
+		{"%-", "%-"},
+		{"%_", "%_"},
+		{"%:", "%:"},
+		{"%Y%", "2024%"},
+	}
+	for _, tt := range tests {
+		if got := strftime(ref, tt.format); got != tt.want {
+			t.Errorf("strftime(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestStrftimeWeeks(t *testing.T) {
+	// Week numbers around the turn of the year, checked against GNU date.
+	tests := []struct {
+		date string
+		want string // "%a %U %W %G %g %V"
+	}{
+		{"2018-12-31", "Mon 52 53 2019 19 01"},
+		{"2020-12-31", "Thu 52 52 2020 20 53"},
+		{"2021-01-01", "Fri 00 00 2020 20 53"},
+		{"2021-01-03", "Sun 01 00 2020 20 53"},
+		{"2021-01-04", "Mon 01 01 2021 21 01"},
+		{"2023-01-01", "Sun 01 00 2022 22 52"},
+		{"2023-01-02", "Mon 01 01 2023 23 01"},
+		{"2024-12-30", "Mon 52 53 2025 25 01"},
+		{"2026-01-01", "Thu 00 00 2026 26 01"},
+		{"2027-01-03", "Sun 01 00 2026 26 53"},
+	}
+	for _, tt := range tests {
+		d, err := time.Parse(time.DateOnly, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strftime(d, "%a %U %W %G %g %V"); got != tt.want {
+			t.Errorf("strftime(%s) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestStrftimeSmallYear(t *testing.T) {
+	d := time.Date(99, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := strftime(d, "%Y %C %y %_Y %-Y"), "0099 00 99   99 99"; got != want {
+		t.Errorf("strftime(year 99) = %q, want %q", got, want)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"12h", 12 * time.Hour, true},
+		{"12h30m", 12*time.Hour + 30*time.Minute, true},
+		{"1.5h", 90 * time.Minute, true},
+		{"+1h", time.Hour, true},
+		{"30d", 30 * day, true},
+		{"2w", 14 * day, true},
+		{"6mo", 6 * month, true},
+		{"1.5y", year + 6*month, true},
+		{"1d12h", 36 * time.Hour, true},
+		{"1y2mo3d", year + 2*month + 3*day, true},
+		{"1m", time.Minute, true},
+		{"1mo", month, true},
+
+		{"", 0, false},
+		{"1", 0, false},
+		{"d", 0, false},
+		{"-1d", 0, false},
+		{"-1h", 0, false},
+		{"1d-2h", 0, false},
+		{"1d+2h", 0, false},
+		{"1e3d", 0, false},
+		{"1h1", 0, false},
+		{"1dd", 0, false},
+		{"1x", 0, false},
+		{"999999999999y", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.s)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v, ok %v", tt.s, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"slices"
+)
+
+// Connectors used to draw the branches of a tree.
+const (
+	treeBranch = "├── "
+	treeLast   = "└── "
+	treePipe   = "│   "
+	treeSpace  = "    "
+)
+
+// printTree prints l's directory as the root of a tree of its contents.
+func printTree(l listing) {
+	showErrors(l.errs)
+
+	root := l.dir
+	root.uiName = tildePath(root.uiName)
+	ents := []entry{root}
+	if gitEnabled() {
+		attachGitToFiles(ents)
+	}
+
+	if info, err := os.Stat(root.fullPath); err != nil {
+		showError(err)
+	} else {
+		ents = appendTree(ents, l, "", 1, []os.FileInfo{info})
+	}
+
+	if opt.long {
+		printLong(ents)
+	} else {
+		print1PerLine(ents)
+	}
+	if opt.summary {
+		printSummary(ents[1:])
+	}
+}
+
+// appendTree appends the entries of l to ents in depth-first order, each
+// with its indent set to the connectors that place it in the tree, and
+// returns the extended slice. l's entries are at the given level of depth
+// below the root; ancestors is as for [readSubdirs].
+func appendTree(ents []entry, l listing, indent string, level int, ancestors []os.FileInfo) []entry {
+	children := slices.DeleteFunc(slices.Clone(l.ents), isDotEntry)
+
+	var subdirs []entry
+	var subdirIdx []int
+	if opt.depth == 0 || level < opt.depth {
+		for i, e := range children {
+			if e.dirLike {
+				// Label errors with the path below the root, as -R does.
+				e.uiName = subdirName(l.dir.uiName, e.uiName)
+				subdirs = append(subdirs, e)
+				subdirIdx = append(subdirIdx, i)
+			}
+		}
+	}
+	listings, infos := readSubdirs(subdirs, ancestors)
+
+	j := 0 // index into subdirs
+	for i, e := range children {
+		connector, childIndent := treeBranch, treePipe
+		if i == len(children)-1 {
+			connector, childIndent = treeLast, treeSpace
+		}
+		e.indent = indent + connector
+		ents = append(ents, e)
+
+		if j == len(subdirIdx) || subdirIdx[j] != i {
+			continue
+		}
+		showErrors(listings[j].errs)
+		if infos[j] != nil {
+			ents = appendTree(ents, listings[j], indent+childIndent, level+1,
+				append(slices.Clip(ancestors), infos[j]))
+		}
+		j++
+	}
+	return ents
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// xattr represents an extended attribute of a file.
+type xattr struct {
+	name string
+	size int // size of the value in bytes
+}
+
+// attrIndicator returns the character appended to e's mode in long
+// listings: '+' if it has a POSIX ACL, '@' if it has other extended
+// attributes, '.' if its only attribute is an SELinux context, and 0
+// otherwise.
+func attrIndicator(e entry) byte {
+	attrs, _ := listXattrs(e.fullPath)
+	var acl, other, selinux bool
+	for _, a := range attrs {
+		switch a.name {
+		case "system.posix_acl_access", "system.posix_acl_default":
+			acl = true
+		case "security.selinux":
+			selinux = true
+		default:
+			other = true
+		}
+	}
+	switch {
+	case acl:
+		return '+'
+	case other:
+		return '@'
+	case selinux:
+		return '.'
+	default:
+		return 0
+	}
+}
+
+// modeCell formats the mode column of e, including its attribute indicator.
+func modeCell(e entry) string {
+	s := mode(e)
+	if c := attrIndicator(e); c != 0 {
+		s += string(c)
+	}
+	return colorMode(s)
+}
+
+// securityContext returns the SELinux security context of e, or "?" if it
+// has none.
+func securityContext(e entry) string {
+	v, err := getXattr(e.fullPath, "security.selinux")
+	if ctx := strings.TrimRight(string(v), "\x00"); err == nil && ctx != "" {
+		return ctx
+	}
+	return "?"
+}
+
+// printXattrs prints the names and sizes of e's extended attributes,
+// one per line, like macOS ls -l@.
+func printXattrs(e entry) {
+	attrs, _ := listXattrs(e.fullPath)
+	for _, a := range attrs {
+		fmt.Printf("\t%s\t%4d\n", a.name, a.size)
+	}
+}
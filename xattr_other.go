@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd)
+
+package main
+
+import "errors"
+
+// getXattr returns the value of the extended attribute name of path,
+// which are not supported on this system.
+func getXattr(path, name string) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// listXattrs returns the extended attributes of path, which are not
+// supported on this system.
+func listXattrs(path string) ([]xattr, error) {
+	return nil, nil
+}
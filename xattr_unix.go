@@ -0,0 +1,45 @@
+//go:build linux || darwin || freebsd || netbsd
+
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// getXattr returns the value of the extended attribute name of path,
+// without following symbolic links.
+func getXattr(path, name string) ([]byte, error) {
+	n, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	n, err = unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// listXattrs returns the extended attributes of path, without following
+// symbolic links.
+func listXattrs(path string) ([]xattr, error) {
+	n, err := unix.Llistxattr(path, nil)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	n, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var attrs []xattr
+	for name := range strings.SplitSeq(strings.TrimSuffix(string(buf[:n]), "\x00"), "\x00") {
+		size, _ := unix.Lgetxattr(path, name, nil)
+		attrs = append(attrs, xattr{name, size})
+	}
+	return attrs, nil
+}